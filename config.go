@@ -0,0 +1,130 @@
+package main
+
+import (
+	"breakout/internal/config"
+	"breakout/internal/levels"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// addConfigFlags registers --config, --difficulty and a flag for every config
+// value, such as --game.serves, and returns the loader they configure
+func addConfigFlags(fs *flag.FlagSet) *config.Loader {
+	loader := &config.Loader{Environ: os.Environ()}
+	fs.StringVar(&loader.Path, "config", "", "load the config from this file instead of the user config")
+	loader.Flags = config.RegisterFlags(fs)
+
+	usage := fmt.Sprintf("play on a difficulty preset: %s (short for --game.difficulty)", strings.Join(config.PresetNames(), ", "))
+	fs.Func("difficulty", usage, func(name string) error {
+		loader.Flags["game.difficulty"] = name
+		return nil
+	})
+	return loader
+}
+
+// loadConfig loads the effective config, printing any warnings
+func loadConfig(loader *config.Loader) (*config.Effective, error) {
+	eff, err := loader.Load()
+	if err != nil {
+		return nil, err
+	}
+	for _, warning := range eff.Warnings {
+		fmt.Fprintf(os.Stderr, "breakout: warning: %s\n", warning)
+	}
+	return eff, nil
+}
+
+// runConfig runs the config subcommands
+func runConfig(args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("usage: breakout config check <file>... | breakout config dump [flags]")
+	}
+
+	switch args[0] {
+	case "check":
+		return runConfigCheck(args[1:])
+	case "dump":
+		return runConfigDump(args[1:])
+	default:
+		return fmt.Errorf("unknown config command %q", args[0])
+	}
+}
+
+// runConfigDump prints the effective config after applying the config file,
+// environment and flags, with where each value came from. It never upgrades
+// the config file.
+func runConfigDump(args []string) error {
+	fs := flag.NewFlagSet("config dump", flag.ContinueOnError)
+	loader := addConfigFlags(fs)
+	asJSON := fs.Bool("json", false, "print the effective config as a config file, without sources")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	loader.ReadOnly = true
+
+	eff, err := loadConfig(loader)
+	if err != nil {
+		return err
+	}
+
+	if *asJSON {
+		data, err := config.Marshal(eff.Config)
+		if err != nil {
+			return err
+		}
+		_, err = os.Stdout.Write(data)
+		return err
+	}
+	return eff.Dump(os.Stdout)
+}
+
+// runConfigCheck validates config files, printing every problem found, and
+// fails if any file is invalid
+func runConfigCheck(paths []string) error {
+	if len(paths) == 0 {
+		return fmt.Errorf("usage: breakout config check <file>...")
+	}
+
+	invalid := 0
+	for _, path := range paths {
+		if err := checkConfig(path); err != nil {
+			invalid++
+		}
+	}
+
+	if invalid > 0 {
+		return fmt.Errorf("%d of %d config files invalid", invalid, len(paths))
+	}
+	return nil
+}
+
+// checkConfig validates a single config file without changing it, printing
+// its problems or "ok" along with any warnings
+func checkConfig(path string) error {
+	eff, err := config.Loader{Path: path, ReadOnly: true}.Load()
+	if err == nil {
+		// The game cannot start without its levels
+		if _, levelsErr := levels.Load(eff.Config.Game.LevelsDir); levelsErr != nil {
+			err = &config.ValidationError{Problems: []config.FieldError{{Path: "game.levels_dir", Reason: levelsErr.Error()}}}
+		}
+	}
+
+	var invalid *config.ValidationError
+	switch {
+	case err == nil:
+		for _, warning := range eff.Warnings {
+			fmt.Println(warning)
+		}
+		fmt.Printf("%s: ok\n", path)
+	case errors.As(err, &invalid):
+		for _, problem := range invalid.Problems {
+			fmt.Printf("%s: %v\n", path, problem)
+		}
+	default:
+		fmt.Println(err)
+	}
+	return err
+}
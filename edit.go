@@ -0,0 +1,139 @@
+package main
+
+import (
+	"breakout/internal/config"
+	"breakout/internal/editor"
+	"breakout/internal/game"
+	"breakout/internal/input"
+	"breakout/internal/levels"
+	"breakout/internal/renderer"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
+
+// runEdit opens a level file in the level editor. A file that does not exist
+// is created when the level is first saved. Test games are played with the
+// config, so its flags work here too.
+func runEdit(args []string) error {
+	fs := flag.NewFlagSet("edit", flag.ContinueOnError)
+	loader := addConfigFlags(fs)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		return errors.New("usage: breakout edit [flags] <file.level>")
+	}
+
+	eff, err := loadConfig(loader)
+	if err != nil {
+		return err
+	}
+	ed, err := editor.Open(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+
+	screen, closeWindow := openWindow(eff.Config.Window)
+	defer closeWindow()
+	// Escape goes back from test games and the panel rather than closing the
+	// window
+	rl.SetExitKey(rl.KeyNull)
+
+	keyboard := input.NewKeyboard()
+	keyboard.Bind(bindings(eff.Config.Controls))
+	view := editor.NewScreen(ed, eff.Config, keyboard)
+	defer view.Close()
+
+	runEditor(screen, view, setFullscreen)
+	return nil
+}
+
+// editLevel opens the level being played in the level editor, in the game's
+// window, and returns to the game once the editor is quit. The level is saved
+// to its file in the levels directory, or for a built-in level to a levels
+// directory next to the config file, which then becomes the levels directory,
+// see keepBuiltinEdit. A saved level is played from the next time it starts.
+func editLevel(screen *renderer.Screen, g *game.Game, keyboard *input.Keyboard, loader *config.Loader) {
+	// The settings screen does not tick the game, so its config may be behind
+	// what was just saved there
+	cfg := g.NextConfig()
+	level, i := g.CurrentLevel()
+	path, err := levelPath(cfg.Game.LevelsDir, i, loader)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "breakout: cannot edit the level: %v\n", err)
+		return
+	}
+
+	ed := editor.New(path, level)
+	view := editor.NewScreen(ed, cfg, keyboard)
+	defer view.Close()
+	runEditor(screen, view, func(on bool) {
+		switchFullscreen(g, keyboard, loader, on)
+	})
+
+	saved := ed.Saved()
+	if saved == nil {
+		return
+	}
+	g.ReplaceLevel(saved)
+	if cfg.Game.LevelsDir == "" {
+		if err := keepBuiltinEdit(g, keyboard, loader, filepath.Dir(path)); err != nil {
+			fmt.Fprintf(os.Stderr, "breakout: the edited level is only played until the game is quit: %v\n", err)
+		}
+	}
+}
+
+// keepBuiltinEdit saves dir, which an edited built-in level was saved to, as
+// game.levels_dir, so the edit is still played after a restart. The other
+// built-in levels are copied there first, to be played along with it.
+func keepBuiltinEdit(g *game.Game, keyboard *input.Keyboard, loader *config.Loader, dir string) error {
+	if err := levels.CopyBuiltin(dir); err != nil {
+		return err
+	}
+	return saveSettings(g, keyboard, loader, map[string]string{"game.levels_dir": dir})
+}
+
+// levelPath returns the file the level at index i of the levels in dir is
+// saved to. Built-in levels, with an empty dir, are saved next to the config
+// file, as they cannot be changed.
+func levelPath(dir string, i int, loader *config.Loader) (string, error) {
+	files, err := levels.Files(dir)
+	if err != nil {
+		return "", err
+	}
+	if i >= len(files) {
+		return "", fmt.Errorf("%s: no file for level %d", dir, i+1)
+	}
+	if dir == "" {
+		configPath, err := loader.FilePath()
+		if err != nil {
+			return "", err
+		}
+		dir = filepath.Join(filepath.Dir(configPath), "levels")
+	}
+	return filepath.Join(dir, files[i]), nil
+}
+
+// runEditor runs the editor in the open window until it is quit or the window
+// is closed. The frame the editor is quit on is finished first, so whatever
+// runs next does not see the key that quit it. F11 switches the display mode
+// with fullscreen.
+func runEditor(screen *renderer.Screen, view *editor.Screen, fullscreen func(on bool)) {
+	for !rl.WindowShouldClose() {
+		handleWindowKeys(fullscreen)
+		view.Update(rl.GetFrameTime())
+
+		screen.BeginFrame()
+		view.Draw()
+		screen.EndFrame()
+
+		if view.Done() {
+			return
+		}
+	}
+}
@@ -0,0 +1,89 @@
+package main
+
+import (
+	"breakout/internal/config"
+	"breakout/internal/game"
+	"breakout/internal/input"
+	"breakout/internal/levels"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLevelPath(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", home)
+	loader := &config.Loader{}
+
+	// A built-in level is saved to a copy next to the config file
+	builtin, err := levels.Files("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := levelPath("", 1, loader)
+	if want := filepath.Join(home, "breakout", "levels", builtin[1]); err != nil || got != want {
+		t.Errorf("levelPath() of a built-in level = %q, %v, want %q", got, err, want)
+	}
+
+	// A level from the levels directory is saved over its file
+	dir := t.TempDir()
+	for _, name := range []string{"01-first.level", "02-second.level"} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte("{}\nR\n"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	got, err = levelPath(dir, 1, loader)
+	if want := filepath.Join(dir, "02-second.level"); err != nil || got != want {
+		t.Errorf("levelPath() = %q, %v, want %q", got, err, want)
+	}
+	if _, err := levelPath(dir, 2, loader); err == nil {
+		t.Error("levelPath() of a level without a file error = nil")
+	}
+}
+
+func TestKeepBuiltinEdit(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	loader := &config.Loader{Flags: map[string]string{}}
+	eff, err := loadConfig(loader)
+	if err != nil {
+		t.Fatalf("loadConfig() error = %v", err)
+	}
+	keyboard := input.NewKeyboard()
+	g, err := game.NewHeadless(eff.Config, keyboard)
+	if err != nil {
+		t.Fatalf("NewHeadless() error = %v", err)
+	}
+	g.Initialize()
+
+	// As the editor saves the first built-in level
+	path, err := levelPath("", 0, loader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte("{\"name\": \"Edited\"}\nR\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := keepBuiltinEdit(g, keyboard, loader, dir); err != nil {
+		t.Fatalf("keepBuiltinEdit() error = %v", err)
+	}
+	// As the game loads its levels after a restart
+	eff, err = loadConfig(loader)
+	if err != nil {
+		t.Fatalf("loadConfig() error = %v", err)
+	}
+	if eff.Config.Game.LevelsDir != dir {
+		t.Fatalf("LevelsDir = %q, want %q", eff.Config.Game.LevelsDir, dir)
+	}
+	played, err := levels.Load(eff.Config.Game.LevelsDir)
+	if err != nil || len(played) != len(levels.Builtin()) {
+		t.Fatalf("Load() = %d levels, %v, want one per built-in level", len(played), err)
+	}
+	if played[0].Name != "Edited" {
+		t.Errorf("first level = %q, want the edited one", played[0].Name)
+	}
+}
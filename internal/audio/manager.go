@@ -1,38 +1,71 @@
 package audio
 
 import (
+	"breakout/internal/config"
+
 	rl "github.com/gen2brain/raylib-go/raylib"
 )
 
 // Manager handles all audio operations
 type Manager struct {
+	enabled        bool
 	paddleHitSound rl.Sound
 	brickHitSound  rl.Sound
 }
 
-// New creates a new audio manager and loads sounds
-func New() (*Manager, error) {
-	paddleSound := rl.LoadSound("assets/paddle_hit.wav")
-	brickSound := rl.LoadSound("assets/brick_hit.wav")
+// New creates a new audio manager and loads the configured sounds. When audio
+// is disabled it returns a silent manager.
+func New(cfg config.AudioConfig) (*Manager, error) {
+	if !cfg.Enabled {
+		return NewSilent(), nil
+	}
+
+	paddleSound := rl.LoadSound(cfg.PaddleHitSoundPath)
+	brickSound := rl.LoadSound(cfg.BrickHitSoundPath)
 
-	return &Manager{
+	m := &Manager{
+		enabled:        true,
 		paddleHitSound: paddleSound,
 		brickHitSound:  brickSound,
-	}, nil
+	}
+	m.SetVolume(cfg.Volume)
+	return m, nil
+}
+
+// NewSilent creates an audio manager that never touches the audio device
+func NewSilent() *Manager {
+	return &Manager{}
+}
+
+// SetVolume sets the volume of every sound, from 0 to 1
+func (m *Manager) SetVolume(volume float32) {
+	if !m.enabled {
+		return
+	}
+	rl.SetMasterVolume(volume)
 }
 
 // PlayPaddleHit plays the paddle hit sound
 func (m *Manager) PlayPaddleHit() {
+	if !m.enabled {
+		return
+	}
 	rl.PlaySound(m.paddleHitSound)
 }
 
 // PlayBrickHit plays the brick hit sound
 func (m *Manager) PlayBrickHit() {
+	if !m.enabled {
+		return
+	}
 	rl.PlaySound(m.brickHitSound)
 }
 
 // Cleanup unloads all sounds
 func (m *Manager) Cleanup() {
+	if !m.enabled {
+		return
+	}
 	rl.UnloadSound(m.paddleHitSound)
 	rl.UnloadSound(m.brickHitSound)
 }
\ No newline at end of file
@@ -0,0 +1,253 @@
+// Package bricks defines the kinds of brick a level can be built from. The
+// types are data: the built-in ones are read from types.json.
+package bricks
+
+import (
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"image/color"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Type is a kind of brick
+type Type struct {
+	// ID names the type in rule triggers, such as "red"
+	ID string
+	// Symbol is the character that stands for the type in a level's grid
+	Symbol byte
+	Color  color.RGBA
+	// Points is what a brick of this type scores
+	Points int32
+	// ScoreOn is when the points are scored, ScoreOnHit or ScoreOnBreak
+	ScoreOn string
+	// HitPoints is how many hits a brick of this type takes to break
+	HitPoints int32
+	// Indestructible bricks never break or score, and need not be cleared
+	// to finish a level
+	Indestructible bool
+	// BreakableAfter lists the types that must all be cleared from the level
+	// before a brick of this type can be broken. Until then it is as solid as
+	// an indestructible brick.
+	BreakableAfter []string
+	// ExplosionRadius is how many cells around it a brick of this type
+	// destroys when it breaks, or 0 if it does not explode
+	ExplosionRadius int32
+	// SpeedUp marks the types the ball speeds up on first hitting, in the
+	// difficulty presets' rules
+	SpeedUp bool
+}
+
+// When a brick scores its points
+const (
+	// ScoreOnHit scores a brick's points on every hit, the default
+	ScoreOnHit = "hit"
+	// ScoreOnBreak scores a brick's points once, on the hit that breaks it
+	ScoreOnBreak = "break"
+)
+
+// Registry holds the brick types, in the order they were defined
+type Registry struct {
+	types    []*Type
+	byID     map[string]*Type
+	bySymbol map[byte]*Type
+}
+
+// Empty is the symbol for a grid cell without a brick
+const Empty = '.'
+
+//go:embed types.json
+var builtin []byte
+
+// Default returns the built-in brick types
+var Default = sync.OnceValue(func() *Registry {
+	r, err := Parse(builtin)
+	if err != nil {
+		panic("bricks: built-in types: " + err.Error())
+	}
+	return r
+})
+
+// typeData is a brick type as it is written in data
+type typeData struct {
+	ID        string `json:"id"`
+	Symbol    string `json:"symbol"`
+	Color     string `json:"color"`
+	Points    int32  `json:"points"`
+	ScoreOn   string `json:"score_on"`
+	HitPoints int32  `json:"hit_points"`
+
+	Indestructible  bool     `json:"indestructible"`
+	BreakableAfter  []string `json:"breakable_after"`
+	ExplosionRadius int32    `json:"explosion_radius"`
+	SpeedUp         bool     `json:"speed_up"`
+}
+
+// Parse reads brick types from a JSON list. Every type needs a unique ID, a
+// unique symbol of one printable ASCII character other than Empty, a colour
+// written as "#rrggbb" or "#rrggbbaa" and at least one hit point. Types score
+// on every hit unless score_on says otherwise. A type can only wait on types
+// that can be broken, without waiting on itself through them.
+func Parse(data []byte) (*Registry, error) {
+	var list []typeData
+	if err := json.Unmarshal(data, &list); err != nil {
+		return nil, err
+	}
+
+	r := &Registry{
+		byID:     make(map[string]*Type, len(list)),
+		bySymbol: make(map[byte]*Type, len(list)),
+	}
+	var problems []error
+	for i, d := range list {
+		if d.ScoreOn == "" {
+			d.ScoreOn = ScoreOnHit
+		}
+		fail := func(format string, args ...any) {
+			problems = append(problems, fmt.Errorf("brick type %d (%q): %s", i, d.ID, fmt.Sprintf(format, args...)))
+		}
+
+		c, err := ParseColor(d.Color)
+		switch {
+		case d.ID == "":
+			fail("id must be set")
+		case r.byID[d.ID] != nil:
+			fail("id is already used by another type")
+		case len(d.Symbol) != 1 || d.Symbol[0] <= ' ' || d.Symbol[0] > '~' || d.Symbol[0] == Empty:
+			fail("symbol must be one printable character other than %q, got %q", Empty, d.Symbol)
+		case r.bySymbol[d.Symbol[0]] != nil:
+			fail("symbol %q is already used by another type", d.Symbol)
+		case err != nil:
+			fail("%v", err)
+		case d.HitPoints < 1:
+			fail("hit_points must be at least 1, got %d", d.HitPoints)
+		case d.ScoreOn != ScoreOnHit && d.ScoreOn != ScoreOnBreak:
+			fail("score_on must be %q or %q, got %q", ScoreOnHit, ScoreOnBreak, d.ScoreOn)
+		case d.Indestructible && len(d.BreakableAfter) > 0:
+			fail("an indestructible type cannot be breakable_after other types")
+		case d.ExplosionRadius < 0:
+			fail("explosion_radius must not be negative, got %d", d.ExplosionRadius)
+		case d.Indestructible && d.ExplosionRadius > 0:
+			fail("an indestructible type never breaks, so it cannot explode")
+		default:
+			t := &Type{
+				ID:              d.ID,
+				Symbol:          d.Symbol[0],
+				Color:           c,
+				Points:          d.Points,
+				ScoreOn:         d.ScoreOn,
+				HitPoints:       d.HitPoints,
+				Indestructible:  d.Indestructible,
+				BreakableAfter:  d.BreakableAfter,
+				ExplosionRadius: d.ExplosionRadius,
+				SpeedUp:         d.SpeedUp,
+			}
+			r.types = append(r.types, t)
+			r.byID[t.ID] = t
+			r.bySymbol[t.Symbol] = t
+		}
+	}
+	if err := errors.Join(problems...); err != nil {
+		return nil, err
+	}
+
+	// Types can only wait on each other once they are all read
+	for i, t := range r.types {
+		for _, id := range t.BreakableAfter {
+			other, ok := r.byID[id]
+			switch {
+			case !ok:
+				problems = append(problems, fmt.Errorf("brick type %d (%q): breakable_after: unknown brick type %q", i, t.ID, id))
+			case other == t:
+				problems = append(problems, fmt.Errorf("brick type %d (%q): breakable_after: a type cannot wait on itself", i, t.ID))
+			case other.Indestructible:
+				problems = append(problems, fmt.Errorf("brick type %d (%q): breakable_after: %q is indestructible, so it is never cleared", i, t.ID, id))
+			case r.waitsOn(other, t, nil):
+				problems = append(problems, fmt.Errorf("brick type %d (%q): breakable_after: %q waits on %q in turn, so neither can be broken", i, t.ID, id, t.ID))
+			}
+		}
+	}
+	if err := errors.Join(problems...); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// waitsOn reports whether t can only be broken after target is cleared,
+// directly or through the types it waits on
+func (r *Registry) waitsOn(t, target *Type, seen map[*Type]bool) bool {
+	if t == target {
+		return true
+	}
+	if seen[t] {
+		return false
+	}
+	if seen == nil {
+		seen = make(map[*Type]bool)
+	}
+	seen[t] = true
+	for _, id := range t.BreakableAfter {
+		if next, ok := r.byID[id]; ok && r.waitsOn(next, target, seen) {
+			return true
+		}
+	}
+	return false
+}
+
+// ParseColor reads a colour written as "#rrggbb" or "#rrggbbaa"
+func ParseColor(s string) (color.RGBA, error) {
+	hex, ok := strings.CutPrefix(s, "#")
+	if !ok || (len(hex) != 6 && len(hex) != 8) {
+		return color.RGBA{}, fmt.Errorf("color must be written as #rrggbb or #rrggbbaa, got %q", s)
+	}
+	if len(hex) == 6 {
+		hex += "ff"
+	}
+	n, err := strconv.ParseUint(hex, 16, 32)
+	if err != nil {
+		return color.RGBA{}, fmt.Errorf("color must be written as #rrggbb or #rrggbbaa, got %q", s)
+	}
+	return color.RGBA{R: uint8(n >> 24), G: uint8(n >> 16), B: uint8(n >> 8), A: uint8(n)}, nil
+}
+
+// FormatColor writes a colour the way ParseColor reads it, leaving out the
+// alpha if it is opaque
+func FormatColor(c color.RGBA) string {
+	if c.A == 0xff {
+		return fmt.Sprintf("#%02x%02x%02x", c.R, c.G, c.B)
+	}
+	return fmt.Sprintf("#%02x%02x%02x%02x", c.R, c.G, c.B, c.A)
+}
+
+// Lookup returns the type with the given ID
+func (r *Registry) Lookup(id string) (*Type, bool) {
+	t, ok := r.byID[id]
+	return t, ok
+}
+
+// LookupSymbol returns the type a level's grid writes as symbol
+func (r *Registry) LookupSymbol(symbol byte) (*Type, bool) {
+	t, ok := r.bySymbol[symbol]
+	return t, ok
+}
+
+// MustLookup returns the type with the given ID, which must exist
+func (r *Registry) MustLookup(id string) *Type {
+	t, ok := r.byID[id]
+	if !ok {
+		panic(fmt.Sprintf("bricks: no type %q", id))
+	}
+	return t
+}
+
+// IDs returns the ID of every type in the order they were defined
+func (r *Registry) IDs() []string {
+	ids := make([]string, len(r.types))
+	for i, t := range r.types {
+		ids[i] = t.ID
+	}
+	return ids
+}
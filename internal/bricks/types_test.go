@@ -0,0 +1,110 @@
+package bricks
+
+import (
+	"image/color"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestDefaultTypes(t *testing.T) {
+	r := Default()
+	if want := []string{"red", "orange", "green", "yellow", "silver", "steel", "sealed", "explosive"}; !slices.Equal(r.IDs(), want) {
+		t.Errorf("IDs() = %v, want %v", r.IDs(), want)
+	}
+
+	// The classic wall scores 7, 5, 3 and 1 from the top
+	points := map[string]int32{"red": 7, "orange": 5, "green": 3, "yellow": 1}
+	for id, want := range points {
+		if got := r.MustLookup(id); got.Points != want || got.HitPoints != 1 || got.ScoreOn != ScoreOnHit {
+			t.Errorf("%s: Points, HitPoints, ScoreOn = %d, %d, %s, want %d, 1, hit", id, got.Points, got.HitPoints, got.ScoreOn, want)
+		}
+	}
+	// Silver bricks take three hits and only score the last
+	if got := r.MustLookup("silver"); got.HitPoints != 3 || got.ScoreOn != ScoreOnBreak {
+		t.Errorf("silver: HitPoints, ScoreOn = %d, %s, want 3, break", got.HitPoints, got.ScoreOn)
+	}
+	if !r.MustLookup("steel").Indestructible {
+		t.Error("steel is not indestructible")
+	}
+	if got := r.MustLookup("sealed").BreakableAfter; !slices.Equal(got, []string{"red"}) {
+		t.Errorf("sealed BreakableAfter = %v, want red", got)
+	}
+	if got := r.MustLookup("explosive").ExplosionRadius; got != 1 {
+		t.Errorf("explosive ExplosionRadius = %d, want 1", got)
+	}
+	// The ball speeds up on reaching the top two rows
+	for _, id := range r.IDs() {
+		if got, want := r.MustLookup(id).SpeedUp, id == "red" || id == "orange"; got != want {
+			t.Errorf("%s SpeedUp = %v, want %v", id, got, want)
+		}
+	}
+	if got := r.MustLookup("red").Color; got != (color.RGBA{R: 230, G: 41, B: 55, A: 255}) {
+		t.Errorf("red Color = %v, want raylib's red", got)
+	}
+}
+
+func TestParse(t *testing.T) {
+	r, err := Parse([]byte(`[{"id": "glass", "symbol": "#", "color": "#80c0ff80", "points": 2, "score_on": "break", "hit_points": 3}]`))
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	if got, ok := r.LookupSymbol('#'); !ok || got.ID != "glass" {
+		t.Errorf("LookupSymbol('#') = %+v, %v, want glass", got, ok)
+	}
+	glass, ok := r.Lookup("glass")
+	if !ok || glass.Color != (color.RGBA{R: 0x80, G: 0xc0, B: 0xff, A: 0x80}) || glass.HitPoints != 3 || glass.ScoreOn != ScoreOnBreak {
+		t.Errorf("Lookup(glass) = %+v, %v", glass, ok)
+	}
+	if _, ok := r.Lookup("red"); ok {
+		t.Error("Lookup(red) found a type the data does not define")
+	}
+}
+
+func TestFormatColor(t *testing.T) {
+	for _, s := range []string{"#e62937", "#80c0ff80", "#000000"} {
+		c, err := ParseColor(s)
+		if err != nil {
+			t.Fatalf("ParseColor(%q) error = %v", s, err)
+		}
+		if got := FormatColor(c); got != s {
+			t.Errorf("FormatColor(ParseColor(%q)) = %q", s, got)
+		}
+	}
+}
+
+func TestParseRejects(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		want string
+	}{
+		{"Missing ID", `[{"color": "#ffffff", "hit_points": 1}]`, "id must be set"},
+		{"Duplicate ID", `[{"id": "a", "symbol": "a", "color": "#ffffff", "hit_points": 1}, {"id": "a", "symbol": "b", "color": "#000000", "hit_points": 1}]`, "id is already used"},
+		{"No symbol", `[{"id": "a", "color": "#ffffff", "hit_points": 1}]`, "symbol must be one printable character"},
+		{"Empty symbol", `[{"id": "a", "symbol": ".", "color": "#ffffff", "hit_points": 1}]`, "symbol must be one printable character"},
+		{"Long symbol", `[{"id": "a", "symbol": "ab", "color": "#ffffff", "hit_points": 1}]`, "symbol must be one printable character"},
+		{"Duplicate symbol", `[{"id": "a", "symbol": "a", "color": "#ffffff", "hit_points": 1}, {"id": "b", "symbol": "a", "color": "#000000", "hit_points": 1}]`, "symbol \"a\" is already used"},
+		{"Named colour", `[{"id": "a", "symbol": "a", "color": "red", "hit_points": 1}]`, "#rrggbb"},
+		{"Bad hex", `[{"id": "a", "symbol": "a", "color": "#ggffff", "hit_points": 1}]`, "#rrggbb"},
+		{"No hit points", `[{"id": "a", "symbol": "a", "color": "#ffffff"}]`, "hit_points must be at least 1"},
+		{"Unknown score_on", `[{"id": "a", "symbol": "a", "color": "#ffffff", "hit_points": 1, "score_on": "miss"}]`, `score_on must be "hit" or "break"`},
+		{"Not a list", `{"id": "a"}`, "cannot unmarshal"},
+		{"Indestructible and conditional", `[{"id": "a", "symbol": "a", "color": "#ffffff", "hit_points": 1, "indestructible": true, "breakable_after": ["b"]}]`, "an indestructible type cannot be breakable_after"},
+		{"Waits on an unknown type", `[{"id": "a", "symbol": "a", "color": "#ffffff", "hit_points": 1, "breakable_after": ["b"]}]`, `unknown brick type "b"`},
+		{"Negative explosion radius", `[{"id": "a", "symbol": "a", "color": "#ffffff", "hit_points": 1, "explosion_radius": -1}]`, "explosion_radius must not be negative"},
+		{"Indestructible and explosive", `[{"id": "a", "symbol": "a", "color": "#ffffff", "hit_points": 1, "indestructible": true, "explosion_radius": 2}]`, "cannot explode"},
+		{"Waits on itself", `[{"id": "a", "symbol": "a", "color": "#ffffff", "hit_points": 1, "breakable_after": ["a"]}]`, "cannot wait on itself"},
+		{"Waits on an indestructible type", `[{"id": "a", "symbol": "a", "color": "#ffffff", "hit_points": 1, "breakable_after": ["b"]}, {"id": "b", "symbol": "b", "color": "#ffffff", "hit_points": 1, "indestructible": true}]`, `"b" is indestructible`},
+		{"Waits in a circle", `[{"id": "a", "symbol": "a", "color": "#ffffff", "hit_points": 1, "breakable_after": ["b"]}, {"id": "b", "symbol": "b", "color": "#ffffff", "hit_points": 1, "breakable_after": ["c"]}, {"id": "c", "symbol": "c", "color": "#ffffff", "hit_points": 1, "breakable_after": ["a"]}]`, `"b" waits on "a" in turn`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse([]byte(tt.data))
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Parse() error = %v, want it to mention %q", err, tt.want)
+			}
+		})
+	}
+}
@@ -2,34 +2,81 @@ package config
 
 // Config holds all game configuration values
 type Config struct {
-	Window WindowConfig `json:"window"`
-	Game   GameConfig   `json:"game"`
-	Audio  AudioConfig  `json:"audio"`
+	Window   WindowConfig   `json:"window"`
+	Game     GameConfig     `json:"game"`
+	Audio    AudioConfig    `json:"audio"`
+	Controls ControlsConfig `json:"controls"`
 }
 
-// WindowConfig holds window-related settings
+// WindowConfig holds window-related settings. The window's size does not
+// change the playfield, which is scaled to fit it.
 type WindowConfig struct {
-	Width     int32 `json:"width"`
-	Height    int32 `json:"height"`
+	Width     int32  `json:"width"`
+	Height    int32  `json:"height"`
 	Title     string `json:"title"`
-	TargetFPS int32 `json:"target_fps"`
+	TargetFPS int32  `json:"target_fps"`
+	// Resizable lets the window be resized by dragging its edges
+	Resizable bool `json:"resizable"`
+	// Fullscreen starts the game in a borderless window covering the
+	// monitor. F11 switches at any time.
+	Fullscreen bool `json:"fullscreen"`
 }
 
 // GameConfig holds game-related settings
 type GameConfig struct {
-	MaxLevels         int32   `json:"max_levels"`
-	BallBaseSpeed     float32 `json:"ball_base_speed"`
-	BallSpeedIncrement float32 `json:"ball_speed_increment"`
-	PaddleBaseSpeed   float32 `json:"paddle_base_speed"`
-	BricksPerRow      int32   `json:"bricks_per_row"`
-	BricksPerCol      int32   `json:"bricks_per_col"`
+	BallBaseSpeed   float32 `json:"ball_base_speed"`
+	PaddleBaseSpeed float32 `json:"paddle_base_speed"`
+	// LevelsDir is the directory the levels are read from, played in the
+	// order of their file names. Empty plays the built-in levels. See the
+	// levels package.
+	LevelsDir string `json:"levels_dir"`
+
+	// PaddleEnglish is the fraction of the paddle's horizontal velocity the
+	// ball picks up when it bounces off the paddle's top. 0 is classic play.
+	PaddleEnglish float32 `json:"paddle_english"`
+	// SpinTransfer is how much spin, in radians per second of curve, the ball
+	// picks up per pixel per second of paddle velocity. 0 disables spin.
+	SpinTransfer float32 `json:"spin_transfer"`
+	// SpinDecay is the fraction of its spin the ball loses per second
+	SpinDecay float32 `json:"spin_decay"`
+
+	// Difficulty names the preset the values above are taken from, or
+	// "custom" for none. See presets.go.
+	Difficulty string `json:"difficulty"`
+	// Serves is the number of balls the player gets per game
+	Serves int32 `json:"serves"`
+	// Rules set how the game progresses, such as when the ball speeds up.
+	// See rules.go.
+	Rules []Rule `json:"rules"`
 }
 
 // AudioConfig holds audio-related settings
 type AudioConfig struct {
-	Enabled           bool   `json:"enabled"`
+	Enabled            bool   `json:"enabled"`
 	PaddleHitSoundPath string `json:"paddle_hit_sound_path"`
 	BrickHitSoundPath  string `json:"brick_hit_sound_path"`
+	// Volume scales every sound, from 0 for silence to 1 for full volume
+	Volume float32 `json:"volume"`
+}
+
+// Bounds of the paddle's speed setting
+const (
+	MinPaddleSpeed = 1
+	MaxPaddleSpeed = 5
+)
+
+// ControlsConfig holds the keys the player uses, named as in "a", "space" or
+// "left_shift", and the paddle's starting speed setting
+type ControlsConfig struct {
+	Left    string `json:"left"`
+	Right   string `json:"right"`
+	Faster  string `json:"faster"`
+	Slower  string `json:"slower"`
+	Serve   string `json:"serve"`
+	Restart string `json:"restart"`
+	// PaddleSpeed is the paddle's speed setting at the start of a game, from
+	// MinPaddleSpeed to MaxPaddleSpeed. Faster and Slower change it in play.
+	PaddleSpeed int32 `json:"paddle_speed"`
 }
 
 // Default returns the default configuration
@@ -40,19 +87,23 @@ func Default() Config {
 			Height:    1024,
 			Title:     "Breakout",
 			TargetFPS: 144,
+			Resizable: true,
 		},
-		Game: GameConfig{
-			MaxLevels:         2,
-			BallBaseSpeed:     0.4,
-			BallSpeedIncrement: 1.1,
-			PaddleBaseSpeed:   0.3,
-			BricksPerRow:      14,
-			BricksPerCol:      8,
-		},
+		Game: normal.Apply(GameConfig{}),
 		Audio: AudioConfig{
-			Enabled:           true,
+			Enabled:            true,
 			PaddleHitSoundPath: "assets/paddle_hit.wav",
 			BrickHitSoundPath:  "assets/brick_hit.wav",
+			Volume:             1,
+		},
+		Controls: ControlsConfig{
+			Left:        "a",
+			Right:       "d",
+			Faster:      "w",
+			Slower:      "s",
+			Serve:       "space",
+			Restart:     "r",
+			PaddleSpeed: 2,
 		},
 	}
-}
\ No newline at end of file
+}
@@ -0,0 +1,52 @@
+package config
+
+// keyCodes names the keys that can be bound to a control, as they are written
+// in the config, with their key codes. The codes are the ones raylib uses, so
+// the input package can poll them directly.
+var keyCodes = map[string]int32{
+	"space":         32,
+	"enter":         257,
+	"tab":           258,
+	"backspace":     259,
+	"right":         262,
+	"left":          263,
+	"down":          264,
+	"up":            265,
+	"left_shift":    340,
+	"left_control":  341,
+	"left_alt":      342,
+	"right_shift":   344,
+	"right_control": 345,
+	"right_alt":     346,
+	"comma":         44,
+	"period":        46,
+	"slash":         47,
+	"semicolon":     59,
+}
+
+func init() {
+	// Letters and digits are their upper case ASCII codes
+	for c := 'a'; c <= 'z'; c++ {
+		keyCodes[string(c)] = int32(c - 'a' + 'A')
+	}
+	for c := '0'; c <= '9'; c++ {
+		keyCodes[string(c)] = int32(c)
+	}
+}
+
+// KeyCode returns the code of the key with the given config name, such as "a"
+// or "left_shift"
+func KeyCode(name string) (int32, bool) {
+	code, ok := keyCodes[name]
+	return code, ok
+}
+
+// KeyName returns the config name of a key code, or "" if it cannot be bound
+func KeyName(code int32) string {
+	for name, c := range keyCodes {
+		if c == code {
+			return name
+		}
+	}
+	return ""
+}
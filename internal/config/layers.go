@@ -0,0 +1,354 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+)
+
+// EnvPrefix starts the name of every environment variable that overrides a
+// config value, as in BREAKOUT_GAME_MAX_LEVELS
+const EnvPrefix = "BREAKOUT_"
+
+// Layers a value can come from, from lowest to highest precedence
+const (
+	LayerDefault = "default"
+	LayerPreset  = "preset"
+	LayerFile    = "file"
+	LayerEnv     = "env"
+	LayerFlag    = "flag"
+)
+
+// Origin is where an effective config value came from
+type Origin struct {
+	// Layer is one of LayerDefault, LayerPreset, LayerFile, LayerEnv or
+	// LayerFlag
+	Layer string
+	// Name is the preset, file path, variable or flag that set the value
+	Name string
+}
+
+func (o Origin) String() string {
+	if o.Name == "" {
+		return o.Layer
+	}
+	return o.Layer + " " + o.Name
+}
+
+// Effective is a config merged from all its layers
+type Effective struct {
+	Config Config
+	// Origins maps every value's path to the layer that set it
+	Origins map[string]Origin
+	// Warnings lists anything ignored while loading, such as unknown
+	// environment variables
+	Warnings []string
+}
+
+// Loader builds the effective config from its layers. Each layer overrides
+// the ones before it: the defaults, then the difficulty preset named by
+// game.difficulty, then the config file, then BREAKOUT_* environment
+// variables, then command-line flags.
+type Loader struct {
+	// Path is the config file, or empty for the user's config file, which
+	// may be missing
+	Path string
+	// Environ is the environment in os.Environ's form
+	Environ []string
+	// Flags maps value paths to the values given on the command line
+	Flags map[string]string
+	// Difficulty, if set, replaces the default difficulty, as when it is
+	// picked from a menu. Every layer above the defaults still overrides it.
+	Difficulty string
+	// ReadOnly leaves a config file of an older version as it is on disk,
+	// upgrading it in memory only
+	ReadOnly bool
+}
+
+// RegisterFlags adds a flag for every config value to fs, named by its path as
+// in --game.max_levels=5. The returned map fills in as fs parses.
+func RegisterFlags(fs *flag.FlagSet) map[string]string {
+	values := make(map[string]string)
+	defaults := Default()
+	for _, f := range fields(&defaults) {
+		usage := fmt.Sprintf("override %s (default %s)", f.path, f.format())
+		set := func(s string) error {
+			values[f.path] = s
+			return nil
+		}
+		// Bool flags may be given without a value, as in --audio.enabled
+		if f.value.Kind() == reflect.Bool {
+			fs.BoolFunc(f.path, usage, set)
+		} else {
+			fs.Func(f.path, usage, set)
+		}
+	}
+	return values
+}
+
+// Paths returns the path of every config value in declaration order
+func Paths() []string {
+	var cfg Config
+	fs := fields(&cfg)
+	paths := make([]string, len(fs))
+	for i, f := range fs {
+		paths[i] = f.path
+	}
+	return paths
+}
+
+// EnvName returns the environment variable that overrides the value at path
+func EnvName(path string) string {
+	return EnvPrefix + strings.ToUpper(strings.ReplaceAll(path, ".", "_"))
+}
+
+// FilePath returns the config file the loader reads
+func (l Loader) FilePath() (string, error) {
+	return ResolvePath(l.Path)
+}
+
+// Load merges the layers and validates the result. Problems with values from
+// any layer are reported together as a *ValidationError.
+func (l Loader) Load() (*Effective, error) {
+	eff := &Effective{Config: Default(), Origins: make(map[string]Origin)}
+	byPath := make(map[string]field)
+	for _, f := range fields(&eff.Config) {
+		byPath[f.path] = f
+		eff.Origins[f.path] = Origin{Layer: LayerDefault}
+	}
+	if l.Difficulty != "" {
+		eff.Config.Game.Difficulty = l.Difficulty
+	}
+
+	if err := l.loadFile(eff); err != nil {
+		return nil, err
+	}
+
+	var problems []FieldError
+	set := func(path, value string, origin Origin) {
+		if err := byPath[path].set(value); err != nil {
+			problems = append(problems, FieldError{
+				Path:   path,
+				Reason: fmt.Sprintf("invalid value %q from %s: %v", value, origin, err),
+			})
+			return
+		}
+		eff.Origins[path] = origin
+	}
+
+	envPaths := make(map[string]string)
+	for _, path := range Paths() {
+		envPaths[EnvName(path)] = path
+	}
+	for _, kv := range l.Environ {
+		name, value, _ := strings.Cut(kv, "=")
+		if !strings.HasPrefix(name, EnvPrefix) {
+			continue
+		}
+		path, ok := envPaths[name]
+		if !ok {
+			eff.Warnings = append(eff.Warnings, fmt.Sprintf("ignoring unknown environment variable %s", name))
+			continue
+		}
+		set(path, value, Origin{Layer: LayerEnv, Name: name})
+	}
+
+	// Apply flags in declaration order so errors come out in a stable order
+	for _, path := range Paths() {
+		if value, ok := l.Flags[path]; ok {
+			set(path, value, Origin{Layer: LayerFlag, Name: "--" + path})
+		}
+	}
+
+	eff.applyPreset()
+
+	var invalid *ValidationError
+	if err := Validate(eff.Config); errors.As(err, &invalid) {
+		for _, p := range invalid.Problems {
+			if origin := eff.Origins[p.Path]; origin.Layer == LayerEnv || origin.Layer == LayerFlag {
+				p.Reason += " (set by " + origin.String() + ")"
+			}
+			problems = append(problems, p)
+		}
+	}
+	if len(problems) > 0 {
+		return nil, &ValidationError{Problems: problems}
+	}
+	return eff, nil
+}
+
+// applyPreset sets every value the chosen difficulty preset controls that no
+// layer has set, as if the preset were a layer right above the defaults. An
+// unknown difficulty is left for Validate to report.
+func (e *Effective) applyPreset() {
+	name := e.Config.Game.Difficulty
+	preset, ok := LookupPreset(name)
+	if !ok {
+		return
+	}
+
+	applied := e.Config
+	applied.Game = preset.Apply(applied.Game)
+	from := fields(&applied)
+	for i, f := range fields(&e.Config) {
+		if presetControls(f.path) && e.Origins[f.path].Layer == LayerDefault {
+			f.value.Set(from[i].value)
+			e.Origins[f.path] = Origin{Layer: LayerPreset, Name: name}
+		}
+	}
+}
+
+// loadFile applies the config file, if there is one, to eff
+func (l Loader) loadFile(eff *Effective) error {
+	path, err := l.FilePath()
+	if err != nil {
+		if l.Path == "" {
+			return nil
+		}
+		return err
+	}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) && l.Path == "" {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	upgraded, version, err := decodeFile(data, &eff.Config)
+	if err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	if version < Version {
+		eff.Warnings = append(eff.Warnings, l.upgrade(path, version, data, upgraded))
+		data = upgraded
+	}
+
+	// Credit the file only with the values it actually sets
+	set, unknown := fileKeys(data)
+	for _, p := range set {
+		eff.Origins[p] = Origin{Layer: LayerFile, Name: path}
+	}
+	for _, p := range unknown {
+		eff.Warnings = append(eff.Warnings, fmt.Sprintf("%s: ignoring unknown key %s", path, p))
+	}
+	return nil
+}
+
+// upgrade saves a config file upgraded from an older version, unless the
+// loader is read-only, and describes what it did
+func (l Loader) upgrade(path string, version int, original, upgraded []byte) string {
+	if l.ReadOnly {
+		return fmt.Sprintf("%s: config version %d is out of date, loading it as version %d", path, version, Version)
+	}
+	if err := upgradeFile(path, original, upgraded); err != nil {
+		return fmt.Sprintf("%s: config version %d is out of date and could not be upgraded: %v", path, version, err)
+	}
+	return fmt.Sprintf("%s: upgraded config from version %d to %d, the original is saved as %s.bak", path, version, Version, path)
+}
+
+// Dump writes every effective value and its origin as an aligned table
+func (e *Effective) Dump(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "PATH\tVALUE\tSOURCE")
+	cfg := e.Config
+	for _, f := range fields(&cfg) {
+		fmt.Fprintf(tw, "%s\t%s\t%s\n", f.path, f.format(), e.Origins[f.path])
+	}
+	return tw.Flush()
+}
+
+// field is a single config value, addressed by its JSON path
+type field struct {
+	path  string
+	value reflect.Value
+}
+
+// fields lists the values of cfg in declaration order. The values are
+// addressable, so setting them changes cfg.
+func fields(cfg *Config) []field {
+	var out []field
+	var walk func(prefix string, v reflect.Value)
+	walk = func(prefix string, v reflect.Value) {
+		t := v.Type()
+		for i := range t.NumField() {
+			name, _, _ := strings.Cut(t.Field(i).Tag.Get("json"), ",")
+			if name == "" || name == "-" {
+				continue
+			}
+			if prefix != "" {
+				name = prefix + "." + name
+			}
+			if v.Field(i).Kind() == reflect.Struct {
+				walk(name, v.Field(i))
+				continue
+			}
+			out = append(out, field{path: name, value: v.Field(i)})
+		}
+	}
+	walk("", reflect.ValueOf(cfg).Elem())
+	return out
+}
+
+// set parses s into the value according to its type
+func (f field) set(s string) error {
+	switch f.value.Kind() {
+	case reflect.String:
+		f.value.SetString(s)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return errors.New("must be true or false")
+		}
+		f.value.SetBool(b)
+	case reflect.Int32:
+		n, err := strconv.ParseInt(s, 10, 32)
+		if err != nil {
+			return errors.New("must be a whole number")
+		}
+		f.value.SetInt(n)
+	case reflect.Float32:
+		n, err := strconv.ParseFloat(s, 32)
+		if err != nil {
+			return errors.New("must be a number")
+		}
+		f.value.SetFloat(n)
+	case reflect.Slice:
+		// Lists, such as the rules, are given whole as JSON
+		list := reflect.New(f.value.Type())
+		if err := json.Unmarshal([]byte(s), list.Interface()); err != nil {
+			return fmt.Errorf("must be a JSON list: %v", err)
+		}
+		f.value.Set(list.Elem())
+	default:
+		return fmt.Errorf("unsupported type %s", f.value.Type())
+	}
+	return nil
+}
+
+// format renders the value the way set parses it
+func (f field) format() string {
+	switch f.value.Kind() {
+	case reflect.String:
+		return strconv.Quote(f.value.String())
+	case reflect.Float32:
+		return strconv.FormatFloat(f.value.Float(), 'g', -1, 32)
+	case reflect.Slice:
+		data, err := json.Marshal(f.value.Interface())
+		if err != nil {
+			return err.Error()
+		}
+		return string(data)
+	default:
+		return fmt.Sprint(f.value.Interface())
+	}
+}
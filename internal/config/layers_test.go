@@ -0,0 +1,215 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"io/fs"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestLoaderPrecedence(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	writeFile(t, path, `{"game": {"serves": 3, "ball_base_speed": 0.5}, "audio": {"enabled": true}}`)
+
+	l := Loader{
+		Path:    path,
+		Environ: []string{"HOME=/root", "BREAKOUT_GAME_SERVES=4", "BREAKOUT_WINDOW_TITLE=From env"},
+		Flags:   map[string]string{"game.serves": "5"},
+	}
+	eff, err := l.Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	want := Default()
+	want.Game.Serves = 5
+	want.Game.BallBaseSpeed = 0.5
+	want.Window.Title = "From env"
+	if !reflect.DeepEqual(eff.Config, want) {
+		t.Errorf("Load() = %+v, want %+v", eff.Config, want)
+	}
+
+	origins := map[string]Origin{
+		"game.serves":          {LayerFlag, "--game.serves"},
+		"game.ball_base_speed": {LayerFile, path},
+		"audio.enabled":        {LayerFile, path},
+		"window.title":         {LayerEnv, "BREAKOUT_WINDOW_TITLE"},
+		"window.width":         {LayerDefault, ""},
+	}
+	for p, want := range origins {
+		if got := eff.Origins[p]; got != want {
+			t.Errorf("Origins[%q] = %v, want %v", p, got, want)
+		}
+	}
+	if len(eff.Origins) != len(Paths()) {
+		t.Errorf("len(Origins) = %d, want one per value (%d)", len(eff.Origins), len(Paths()))
+	}
+}
+
+func TestLoaderDifficultyIsADefault(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", dir)
+	l := Loader{Difficulty: "hard"}
+
+	eff, err := l.Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if eff.Config.Game.Difficulty != "hard" || eff.Config.Game.Serves != 2 {
+		t.Errorf("Difficulty, Serves = %q, %d, want the hard preset", eff.Config.Game.Difficulty, eff.Config.Game.Serves)
+	}
+	if got := eff.Origins["game.difficulty"]; got.Layer != LayerDefault {
+		t.Errorf("Origins[game.difficulty] = %v, want the default layer", got)
+	}
+
+	// A difficulty saved to the file replaces it
+	writeFile(t, filepath.Join(dir, "breakout", "config.json"), `{"game": {"difficulty": "easy"}}`)
+	if eff, err = l.Load(); err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if eff.Config.Game.Difficulty != "easy" || eff.Config.Game.Serves != 5 {
+		t.Errorf("Difficulty, Serves = %q, %d, want the easy preset from the file", eff.Config.Game.Difficulty, eff.Config.Game.Serves)
+	}
+}
+
+func TestLoaderFindsUserConfig(t *testing.T) {
+	t.Run("Missing user config gives defaults", func(t *testing.T) {
+		t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+
+		eff, err := Loader{}.Load()
+		if err != nil {
+			t.Fatalf("Load() error = %v", err)
+		}
+		if !reflect.DeepEqual(eff.Config, Default()) {
+			t.Errorf("Load() = %+v, want the defaults", eff.Config)
+		}
+	})
+
+	t.Run("User config is read from XDG_CONFIG_HOME", func(t *testing.T) {
+		dir := t.TempDir()
+		t.Setenv("XDG_CONFIG_HOME", dir)
+		writeFile(t, filepath.Join(dir, "breakout", "config.json"), `{"game": {"serves": 3}}`)
+
+		eff, err := Loader{}.Load()
+		if err != nil {
+			t.Fatalf("Load() error = %v", err)
+		}
+		if eff.Config.Game.Serves != 3 {
+			t.Errorf("Load().Game.Serves = %d, want 3", eff.Config.Game.Serves)
+		}
+	})
+
+	t.Run("Explicit path must exist", func(t *testing.T) {
+		_, err := Loader{Path: filepath.Join(t.TempDir(), "missing.json")}.Load()
+		if !errors.Is(err, fs.ErrNotExist) {
+			t.Errorf("Load() error = %v, want %v", err, fs.ErrNotExist)
+		}
+	})
+}
+
+func TestLoaderReportsBadOverrides(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+
+	l := Loader{
+		Environ: []string{"BREAKOUT_GAME_SERVES=many", "BREAKOUT_GAME_MAX_LEVEL=3"},
+		Flags:   map[string]string{"game.ball_base_speed": "-1", "audio.enabled": "yes please"},
+	}
+	eff, err := l.Load()
+
+	var invalid *ValidationError
+	if !errors.As(err, &invalid) {
+		t.Fatalf("Load() = %v, %v, want a *ValidationError", eff, err)
+	}
+
+	want := map[string]string{
+		"game.serves":          "BREAKOUT_GAME_SERVES",
+		"audio.enabled":        "--audio.enabled",
+		"game.ball_base_speed": "--game.ball_base_speed",
+	}
+	if len(invalid.Problems) != len(want) {
+		t.Errorf("Load() problems = %v, want %d", invalid.Problems, len(want))
+	}
+	for _, p := range invalid.Problems {
+		if source, ok := want[p.Path]; !ok || !strings.Contains(p.Reason, source) {
+			t.Errorf("problem %q, want it to name %q", p, source)
+		}
+	}
+
+	// Unknown variables with the prefix are warned about, not fatal
+	l.Environ, l.Flags = []string{"BREAKOUT_GAME_MAX_LEVEL=3"}, nil
+	eff, err = l.Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if len(eff.Warnings) != 1 || !strings.Contains(eff.Warnings[0], "BREAKOUT_GAME_MAX_LEVEL") {
+		t.Errorf("Warnings = %q, want one about BREAKOUT_GAME_MAX_LEVEL", eff.Warnings)
+	}
+}
+
+func TestRegisterFlags(t *testing.T) {
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	values := RegisterFlags(fs)
+
+	if err := fs.Parse([]string{"--game.serves=5", "--window.title", "Speedrun", "rest"}); err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	want := map[string]string{"game.serves": "5", "window.title": "Speedrun"}
+	if len(values) != len(want) || values["game.serves"] != "5" || values["window.title"] != "Speedrun" {
+		t.Errorf("flag values = %v, want %v", values, want)
+	}
+	if fs.Arg(0) != "rest" {
+		t.Errorf("Arg(0) = %q, want rest", fs.Arg(0))
+	}
+}
+
+func TestEveryValueHasAnOverride(t *testing.T) {
+	paths := Paths()
+	if len(paths) != 26 {
+		t.Errorf("len(Paths()) = %d, want 26, update this test when adding values", len(paths))
+	}
+
+	cfg := Default()
+	for _, f := range fields(&cfg) {
+		if f.value.Kind() == reflect.String {
+			continue
+		}
+		if err := f.set(f.format()); err != nil {
+			t.Errorf("%s: set(format()) error = %v, want the formatted default to parse", f.path, err)
+		}
+	}
+	if !reflect.DeepEqual(cfg, Default()) {
+		t.Errorf("setting every value to its formatted default changed the config to %+v", cfg)
+	}
+
+	if got := EnvName("game.serves"); got != "BREAKOUT_GAME_SERVES" {
+		t.Errorf("EnvName() = %q, want BREAKOUT_GAME_SERVES", got)
+	}
+}
+
+func TestDump(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+
+	eff, err := Loader{Flags: map[string]string{"game.serves": "5"}}.Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := eff.Dump(&buf); err != nil {
+		t.Fatalf("Dump() error = %v", err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != len(Paths())+1 {
+		t.Fatalf("Dump() wrote %d lines, want a header and one per value", len(lines))
+	}
+	if got := strings.Fields(lines[1+slices.Index(Paths(), "game.serves")]); !slices.Equal(got, []string{"game.serves", "5", "flag", "--game.serves"}) {
+		t.Errorf("Dump() serves line = %q", got)
+	}
+}
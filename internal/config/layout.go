@@ -0,0 +1,33 @@
+package config
+
+// Fixed sizes of the playfield and its layout, in pixels. The game always
+// simulates this playfield, and it is scaled to fit whatever size the window
+// has. They are not configurable.
+const (
+	// PlayfieldWidth and PlayfieldHeight are the size of the virtual
+	// playfield
+	PlayfieldWidth  = 768
+	PlayfieldHeight = 1024
+	// BrickSpacing is the gap between neighbouring bricks and around the wall
+	BrickSpacing = 5
+	// BrickYOffset is the space above the brick wall
+	BrickYOffset = 200
+	// BrickHeight is the height of a single brick
+	BrickHeight = 10
+	// PaddleMargin is the distance from the paddle's top to the bottom of
+	// the playfield
+	PaddleMargin = 100
+)
+
+// MaxBrickColumns and MaxBrickRows are the largest brick grid that fits the
+// playfield, with room for the paddle below it
+const (
+	MaxBrickColumns = (PlayfieldWidth-1)/BrickSpacing - 1
+	MaxBrickRows    = (PlayfieldHeight - PaddleMargin - BrickYOffset - BrickSpacing - 1) / (BrickHeight + BrickSpacing)
+)
+
+// BrickAreaHeight returns the distance from the top of the playfield to the
+// bottom of a brick wall with the given number of rows
+func BrickAreaHeight(rows int32) int32 {
+	return BrickYOffset + BrickSpacing + rows*(BrickHeight+BrickSpacing)
+}
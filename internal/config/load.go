@@ -0,0 +1,124 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// Load reads and validates a configuration from a JSON file, ignoring the
+// environment. Fields the file leaves out come from its difficulty preset or
+// the defaults. A file of an older version is upgraded in memory only.
+func Load(path string) (Config, error) {
+	eff, err := Loader{Path: path, ReadOnly: true}.Load()
+	if err != nil {
+		return Config{}, err
+	}
+	return eff.Config, nil
+}
+
+// Parse decodes and validates a JSON configuration, upgrading it from an older
+// version if needed. Fields the data leaves out keep their default values.
+// Values of the wrong type are reported as a *ValidationError, like values
+// that fail Validate.
+func Parse(data []byte) (Config, error) {
+	cfg := Default()
+	if _, _, err := decodeFile(data, &cfg); err != nil {
+		return Config{}, err
+	}
+	if err := Validate(cfg); err != nil {
+		return Config{}, err
+	}
+	return cfg, nil
+}
+
+// decode applies the values set in JSON data to cfg without validating them
+func decode(data []byte, cfg *Config) error {
+	// Decoding into a list reuses its elements, which would leave the fields
+	// a file's rule leaves out set from the rule it replaces, so a file that
+	// sets the rules decodes them into a fresh list
+	var keys struct {
+		Game struct {
+			Rules json.RawMessage `json:"rules"`
+		} `json:"game"`
+	}
+	if json.Unmarshal(data, &keys) == nil && keys.Game.Rules != nil {
+		cfg.Game.Rules = nil
+	}
+	err := json.Unmarshal(data, cfg)
+	if err != nil {
+		return describeJSONError(data, err)
+	}
+	return nil
+}
+
+// describeJSONError adds the line and column to a decoding error
+func describeJSONError(data []byte, err error) error {
+	var syntaxErr *json.SyntaxError
+	var typeErr *json.UnmarshalTypeError
+	switch {
+	case errors.As(err, &syntaxErr):
+		// The offset is just past the offending character
+		line, col := position(data, syntaxErr.Offset-1)
+		return fmt.Errorf("line %d, column %d: %w", line, col, err)
+	case errors.As(err, &typeErr):
+		// The offset is at the end of the value, so only the line is exact
+		line, _ := position(data, typeErr.Offset)
+		return &ValidationError{Problems: []FieldError{{
+			Path:   indexPath(typeErr.Field),
+			Reason: fmt.Sprintf("must be %s, got %s on line %d", typeErr.Type, typeErr.Value, line),
+		}}}
+	default:
+		return err
+	}
+}
+
+// indexPath writes the list indexes in a decoding error's field path the way
+// Validate does, turning game.rules.0.name into game.rules[0].name
+func indexPath(field string) string {
+	parts := strings.Split(field, ".")
+	var b strings.Builder
+	for i, p := range parts {
+		if _, err := strconv.Atoi(p); err == nil && i > 0 {
+			b.WriteString("[" + p + "]")
+			continue
+		}
+		if i > 0 {
+			b.WriteByte('.')
+		}
+		b.WriteString(p)
+	}
+	return b.String()
+}
+
+// position converts a byte offset into a 1-based line and column
+func position(data []byte, offset int64) (line, col int) {
+	offset = max(0, min(offset, int64(len(data))))
+	before := data[:offset]
+	line = bytes.Count(before, []byte("\n")) + 1
+	col = int(offset) - bytes.LastIndexByte(before, '\n')
+	return line, col
+}
+
+// UserPath returns where the user's config file lives, following the XDG base
+// directory spec on Linux: $XDG_CONFIG_HOME/breakout/config.json
+func UserPath() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "breakout", "config.json"), nil
+}
+
+// ResolvePath returns path, or the user's config file path when path is empty
+func ResolvePath(path string) (string, error) {
+	if path != "" {
+		return path, nil
+	}
+	return UserPath()
+}
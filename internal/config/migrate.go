@@ -0,0 +1,346 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Version is the config file format this build reads and writes. Files
+// without a "version" key are version 1.
+const Version = 4
+
+// migrations upgrade a decoded config file one version at a time:
+// migrations[i] turns version i+1 into version i+2. Every change to the file
+// format that renames, moves or reinterprets a key needs a new entry here and
+// a bump of Version. Adding a key with a default does not.
+var migrations = []func(doc map[string]any){
+	// Version 2 introduced the "version" key itself. Version 1 files were
+	// written before versioning and use the same keys.
+	func(doc map[string]any) {},
+	// Version 3 replaced game.ball_speed_increment, game.shrink_paddle and
+	// game.speed_ups with game.rules
+	migrateToRules,
+	// Version 4 moved the brick grid and the number of levels into level
+	// files, see game.levels_dir
+	migrateToLevels,
+}
+
+// legacyRules describes what game.ball_speed_increment, game.shrink_paddle
+// and game.speed_ups gave in version 2
+type legacyRules struct {
+	increment float64
+	shrink    bool
+	speedUps  map[string]bool
+}
+
+// legacyPresets are the version 2 difficulty presets' legacy rules
+var legacyPresets = map[string]legacyRules{
+	"easy":   {1.05, false, map[string]bool{"twelve_hits": true, "red_contact": true}},
+	"normal": {1.1, true, map[string]bool{"four_hits": true, "twelve_hits": true, "orange_contact": true, "red_contact": true}},
+	"hard":   {1.15, true, map[string]bool{"four_hits": true, "twelve_hits": true, "orange_contact": true, "red_contact": true}},
+	"atari":  {1.1, true, map[string]bool{"four_hits": true, "twelve_hits": true, "orange_contact": true, "red_contact": true}},
+}
+
+// migrateToRules turns the version 2 legacy rules into a rules list. A file
+// that sets none of them leaves the rules to its difficulty preset, as before.
+func migrateToRules(doc map[string]any) {
+	game, ok := doc["game"].(map[string]any)
+	if !ok {
+		return
+	}
+	increment, hasIncrement := game["ball_speed_increment"].(json.Number)
+	shrink, hasShrink := game["shrink_paddle"].(bool)
+	speedUps, hasSpeedUps := game["speed_ups"].(map[string]any)
+	delete(game, "ball_speed_increment")
+	delete(game, "shrink_paddle")
+	delete(game, "speed_ups")
+	if !hasIncrement && !hasShrink && !hasSpeedUps {
+		return
+	}
+
+	// Start from what the file's difficulty set, which was normal's values
+	// for a custom difficulty
+	name, _ := game["difficulty"].(string)
+	legacy, ok := legacyPresets[name]
+	if !ok {
+		legacy = legacyPresets["normal"]
+	}
+	legacy.speedUps = maps.Clone(legacy.speedUps)
+
+	if f, err := increment.Float64(); hasIncrement && err == nil {
+		legacy.increment = f
+	}
+	if hasShrink {
+		legacy.shrink = shrink
+	}
+	for key, on := range speedUps {
+		if on, ok := on.(bool); ok {
+			legacy.speedUps[key] = on
+		}
+	}
+
+	var rules []any
+	for _, rule := range classicRules(float32(legacy.increment)) {
+		on := legacy.speedUps[rule.Name]
+		if rule.Name == shrinkPaddle.Name {
+			on = legacy.shrink
+		}
+		if on {
+			rules = append(rules, toDocument(rule))
+		}
+	}
+	game["rules"] = rules
+}
+
+// migrateToLevels drops game.max_levels, game.bricks_per_row and
+// game.bricks_per_col. The levels now set the brick grid, and the game plays
+// as many levels as there are.
+func migrateToLevels(doc map[string]any) {
+	if game, ok := doc["game"].(map[string]any); ok {
+		delete(game, "max_levels")
+		delete(game, "bricks_per_row")
+		delete(game, "bricks_per_col")
+	}
+}
+
+// toDocument converts a value into the generic form of a decoded config file
+func toDocument(v any) any {
+	data, err := json.Marshal(v)
+	if err != nil {
+		panic(err)
+	}
+	var doc any
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	if err := dec.Decode(&doc); err != nil {
+		panic(err)
+	}
+	return doc
+}
+
+// Marshal encodes a config as a file of the current version
+func Marshal(cfg Config) ([]byte, error) {
+	data, err := json.MarshalIndent(struct {
+		Version int `json:"version"`
+		Config
+	}{Version, cfg}, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(data, '\n'), nil
+}
+
+// migrate upgrades a config file's JSON to the current version. It returns
+// the data unchanged if it is already current, along with the version the
+// file was written in.
+func migrate(data []byte) ([]byte, int, error) {
+	var doc map[string]any
+	dec := json.NewDecoder(bytes.NewReader(data))
+	// Keep numbers exactly as written
+	dec.UseNumber()
+	if err := dec.Decode(&doc); err != nil {
+		// Let the typed decode report it with a position
+		return data, Version, nil
+	}
+
+	version, err := fileVersion(doc)
+	if err != nil {
+		return nil, 0, err
+	}
+	if version == Version {
+		return data, version, nil
+	}
+
+	for _, m := range migrations[version-1:] {
+		m(doc)
+	}
+	out, err := formatDocument(doc)
+	if err != nil {
+		return nil, 0, err
+	}
+	return out, version, nil
+}
+
+// formatDocument encodes a decoded config file as a file of the current
+// version
+func formatDocument(doc map[string]any) ([]byte, error) {
+	doc = maps.Clone(doc)
+	delete(doc, "version")
+
+	out, err := json.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	// Put the version first, where people look for it
+	header := fmt.Sprintf("{\n  \"version\": %d", Version)
+	if len(doc) == 0 {
+		return []byte(header + "\n}\n"), nil
+	}
+	out = append([]byte(header+","), bytes.TrimPrefix(out, []byte("{"))...)
+	return append(out, '\n'), nil
+}
+
+// decodeFile upgrades a config file to the current version and applies its
+// values to cfg. It returns the upgraded file and the version it was written
+// in. Values of the wrong type are reported against the file as written, so
+// their line numbers match what the player sees.
+func decodeFile(data []byte, cfg *Config) ([]byte, int, error) {
+	upgraded, version, err := migrate(data)
+	if err != nil {
+		return nil, 0, err
+	}
+	if version < Version {
+		check := *cfg
+		if err := decode(data, &check); err != nil {
+			return nil, 0, err
+		}
+	}
+	if err := decode(upgraded, cfg); err != nil {
+		return nil, 0, err
+	}
+	return upgraded, version, nil
+}
+
+// fileVersion returns the version a decoded config file was written in
+func fileVersion(doc map[string]any) (int, error) {
+	raw, ok := doc["version"]
+	if !ok {
+		return 1, nil
+	}
+
+	n, isNumber := raw.(json.Number)
+	version, err := strconv.Atoi(string(n))
+	if !isNumber || err != nil || version < 1 {
+		return 0, &ValidationError{Problems: []FieldError{{
+			Path:   "version",
+			Reason: fmt.Sprintf("must be a whole number from 1 to %d, got %v", Version, raw),
+		}}}
+	}
+	if version > Version {
+		return 0, fmt.Errorf("config version %d is newer than this game, which reads up to version %d", version, Version)
+	}
+	return version, nil
+}
+
+// upgradeFile replaces a config file with its upgraded contents, first
+// copying the original to path.bak
+func upgradeFile(path string, original, upgraded []byte) error {
+	if err := os.WriteFile(path+".bak", original, 0o644); err != nil {
+		return err
+	}
+	return replaceFile(path, upgraded)
+}
+
+// replaceFile writes data to the file at path. It writes to a temporary file
+// first so a failure cannot leave half a config.
+func replaceFile(path string, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// fileKeys sorts the keys in a config file into the paths of the values it
+// sets and the paths of keys that are not config values
+func fileKeys(data []byte) (set, unknown []string) {
+	var doc map[string]any
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, nil
+	}
+
+	var cfg Config
+	known := make(map[string]reflect.Type)
+	for _, f := range fields(&cfg) {
+		known[f.path] = f.value.Type()
+	}
+
+	var walk func(prefix string, obj map[string]any)
+	walk = func(prefix string, obj map[string]any) {
+		for key, value := range obj {
+			path := prefix + key
+			inner, isObject := value.(map[string]any)
+			switch t, ok := known[path]; {
+			case ok:
+				set = append(set, path)
+				// Check the keys of lists of objects, such as the rules
+				if list, isList := value.([]any); isList && t.Kind() == reflect.Slice {
+					for i, item := range list {
+						if obj, ok := item.(map[string]any); ok {
+							unknown = append(unknown, unknownFields(fmt.Sprintf("%s[%d].", path, i), obj, t.Elem())...)
+						}
+					}
+				}
+			case isObject && isSection(path):
+				walk(path+".", inner)
+			case path != "version":
+				unknown = append(unknown, path)
+			}
+		}
+	}
+	walk("", doc)
+
+	slices.Sort(set)
+	slices.Sort(unknown)
+	return set, unknown
+}
+
+// unknownFields returns the paths of the keys in obj that are not JSON fields
+// of the struct type t, looking into nested objects
+func unknownFields(prefix string, obj map[string]any, t reflect.Type) []string {
+	if t.Kind() != reflect.Struct {
+		return nil
+	}
+
+	var unknown []string
+	for key, value := range obj {
+		f, ok := jsonField(t, key)
+		if !ok {
+			unknown = append(unknown, prefix+key)
+			continue
+		}
+		if inner, isObject := value.(map[string]any); isObject {
+			unknown = append(unknown, unknownFields(prefix+key+".", inner, f.Type)...)
+		}
+	}
+	return unknown
+}
+
+// jsonField returns the field of struct type t that encodes as the JSON key
+func jsonField(t reflect.Type, key string) (reflect.StructField, bool) {
+	for i := range t.NumField() {
+		name, _, _ := strings.Cut(t.Field(i).Tag.Get("json"), ",")
+		if name == key {
+			return t.Field(i), true
+		}
+	}
+	return reflect.StructField{}, false
+}
+
+// isSection reports whether path names a group of config values, such as
+// "game" or "window"
+func isSection(path string) bool {
+	for _, p := range Paths() {
+		if strings.HasPrefix(p, path+".") {
+			return true
+		}
+	}
+	return false
+}
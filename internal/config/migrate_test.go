@@ -0,0 +1,293 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// historical holds a complete config file as written by every version of the
+// file format, with the values each one should load as. Add the new version
+// here whenever Version changes.
+var historical = []struct {
+	version int
+	file    string
+	want    func() Config
+}{
+	{
+		version: 1,
+		file: `{
+  "window": {"width": 640, "height": 900, "title": "Old", "target_fps": 60},
+  "game": {
+    "max_levels": 4,
+    "ball_base_speed": 0.45,
+    "ball_speed_increment": 1.2,
+    "paddle_base_speed": 0.25,
+    "bricks_per_row": 10,
+    "bricks_per_col": 6,
+    "paddle_english": 0.1,
+    "spin_transfer": 0.0004,
+    "spin_decay": 2
+  },
+  "audio": {
+    "enabled": false,
+    "paddle_hit_sound_path": "a.wav",
+    "brick_hit_sound_path": "b.wav"
+  }
+}`,
+		want: func() Config {
+			cfg := Default()
+			cfg.Window = WindowConfig{Width: 640, Height: 900, Title: "Old", TargetFPS: 60, Resizable: true}
+			cfg.Game.BallBaseSpeed = 0.45
+			cfg.Game.Rules = classicRules(1.2)
+			cfg.Game.PaddleBaseSpeed = 0.25
+			cfg.Game.PaddleEnglish = 0.1
+			cfg.Game.SpinTransfer = 0.0004
+			cfg.Game.SpinDecay = 2
+			cfg.Audio = AudioConfig{PaddleHitSoundPath: "a.wav", BrickHitSoundPath: "b.wav", Volume: 1}
+			return cfg
+		},
+	},
+	{
+		version: 2,
+		file: `{
+  "version": 2,
+  "window": {"width": 1920, "height": 1080, "fullscreen": true},
+  "game": {
+    "difficulty": "hard",
+    "serves": 4,
+    "speed_ups": {"four_hits": false}
+  }
+}`,
+		want: func() Config {
+			cfg := Default()
+			hard, _ := LookupPreset("hard")
+			cfg.Game = hard.Apply(cfg.Game)
+			cfg.Window.Width = 1920
+			cfg.Window.Height = 1080
+			cfg.Window.Fullscreen = true
+			cfg.Game.Serves = 4
+			// Every legacy hard rule but the speed-up after four hits
+			cfg.Game.Rules = classicRules(1.15)[1:]
+			return cfg
+		},
+	},
+	{
+		version: 3,
+		file: `{
+  "version": 3,
+  "game": {
+    "difficulty": "easy",
+    "rules": [
+      {
+        "name": "bonus_ball",
+        "trigger": {"event": "elapsed", "seconds": 60},
+        "effect": {"action": "extra_ball", "count": 1},
+        "policy": "repeat"
+      },
+      {
+        "name": "wide_paddle",
+        "trigger": {"event": "level_start", "level": 2},
+        "effect": {"action": "paddle_width", "factor": 1.5},
+        "policy": "once"
+      }
+    ]
+  }
+}`,
+		want: func() Config {
+			cfg := Default()
+			easy, _ := LookupPreset("easy")
+			cfg.Game = easy.Apply(cfg.Game)
+			cfg.Game.Rules = []Rule{
+				{
+					Name:    "bonus_ball",
+					Trigger: Trigger{Event: EventElapsed, Seconds: 60},
+					Effect:  Effect{Action: ActionExtraBall, Count: 1},
+					Policy:  PolicyRepeat,
+				},
+				{
+					Name:    "wide_paddle",
+					Trigger: Trigger{Event: EventLevelStart, Level: 2},
+					Effect:  Effect{Action: ActionPaddleWidth, Factor: 1.5},
+					Policy:  PolicyOnce,
+				},
+			}
+			return cfg
+		},
+	},
+	{
+		version: 4,
+		file: `{
+  "version": 4,
+  "window": {"title": "My levels"},
+  "game": {
+    "levels_dir": "levels",
+    "serves": 5
+  }
+}`,
+		want: func() Config {
+			cfg := Default()
+			cfg.Window.Title = "My levels"
+			cfg.Game.LevelsDir = "levels"
+			cfg.Game.Serves = 5
+			return cfg
+		},
+	},
+}
+
+func TestHistoricalVersionsLoad(t *testing.T) {
+	if last := historical[len(historical)-1].version; last != Version {
+		t.Fatalf("newest historical config is version %d, want %d", last, Version)
+	}
+
+	for _, h := range historical {
+		t.Run(fmt.Sprintf("Version %d", h.version), func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "config.json")
+			writeFile(t, path, h.file)
+
+			eff, err := Loader{Path: path}.Load()
+			if err != nil {
+				t.Fatalf("Load() error = %v", err)
+			}
+			if want := h.want(); !reflect.DeepEqual(eff.Config, want) {
+				t.Errorf("Load() = %+v, want %+v", eff.Config, want)
+			}
+
+			// Loading again, from the upgraded file if there was an
+			// upgrade, and round-tripping through Marshal give the same
+			again, err := Loader{Path: path}.Load()
+			if err != nil {
+				t.Fatalf("second Load() error = %v", err)
+			}
+			if !reflect.DeepEqual(again.Config, eff.Config) || len(again.Warnings) != 0 {
+				t.Errorf("second Load() = %+v with warnings %q, want the same config and no warnings", again.Config, again.Warnings)
+			}
+
+			data, err := Marshal(eff.Config)
+			if err != nil {
+				t.Fatalf("Marshal() error = %v", err)
+			}
+			if got, err := Parse(data); err != nil || !reflect.DeepEqual(got, eff.Config) {
+				t.Errorf("Parse(Marshal()) = %+v, %v, want %+v", got, err, eff.Config)
+			}
+		})
+	}
+}
+
+func TestUpgradeKeepsBackup(t *testing.T) {
+	v1 := historical[0]
+	path := filepath.Join(t.TempDir(), "config.json")
+	writeFile(t, path, v1.file)
+
+	eff, err := Loader{Path: path}.Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if len(eff.Warnings) != 1 || !strings.Contains(eff.Warnings[0], "upgraded config from version 1 to 4") {
+		t.Errorf("Warnings = %q, want one about the upgrade", eff.Warnings)
+	}
+
+	backup, err := os.ReadFile(path + ".bak")
+	if err != nil || string(backup) != v1.file {
+		t.Errorf("backup = %q, %v, want the original file", backup, err)
+	}
+	upgraded, err := os.ReadFile(path)
+	if err != nil || !strings.Contains(string(upgraded), `"version": 4`) {
+		t.Errorf("upgraded file = %q, %v, want version 4", upgraded, err)
+	}
+	if entries, _ := os.ReadDir(filepath.Dir(path)); len(entries) != 2 {
+		t.Errorf("config directory holds %d files, want the config and its backup", len(entries))
+	}
+}
+
+func TestReadOnlyLoadLeavesFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	writeFile(t, path, historical[0].file)
+
+	if _, err := Load(path); err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	data, _ := os.ReadFile(path)
+	if string(data) != historical[0].file {
+		t.Error("Load() changed the file")
+	}
+	if _, err := os.Stat(path + ".bak"); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("Load() wrote a backup, Stat() error = %v", err)
+	}
+}
+
+func TestUnknownKeysWarn(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	writeFile(t, path, `{
+  "version": 4,
+  "game": {
+    "serves": 3,
+    "max_lives": 5,
+    "rules": [{"name": "r", "trigger": {"event": "ceiling_hit", "wall": "top"}, "effect": {"action": "extra_ball", "count": 1}, "policy": "once"}]
+  },
+  "colours": {"ball": "red"}
+}`)
+
+	eff, err := Loader{Path: path}.Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	var got []string
+	for _, w := range eff.Warnings {
+		got = append(got, strings.TrimPrefix(w, path+": ignoring unknown key "))
+	}
+	if want := []string{"colours", "game.max_lives", "game.rules[0].trigger.wall"}; !slices.Equal(got, want) {
+		t.Errorf("Warnings = %q, want unknown keys %q", eff.Warnings, want)
+	}
+	if got := eff.Origins["game.rules"]; got.Layer != LayerFile {
+		t.Errorf("Origins[game.rules] = %v, want the file", got)
+	}
+}
+
+func TestBadVersion(t *testing.T) {
+	tests := []struct {
+		name string
+		file string
+	}{
+		{"Newer than this game", `{"version": 99}`},
+		{"Not a number", `{"version": "two"}`},
+		{"Zero", `{"version": 0}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := Parse([]byte(tt.file)); err == nil {
+				t.Error("Parse() error = nil, want an error")
+			}
+		})
+	}
+}
+
+func TestLegacyPresetRulesKeepTheirDifficulty(t *testing.T) {
+	// A version 2 file that spelled out its preset's own values still plays
+	// on that preset after the upgrade
+	path := filepath.Join(t.TempDir(), "config.json")
+	writeFile(t, path, `{
+  "version": 2,
+  "game": {
+    "difficulty": "easy",
+    "ball_speed_increment": 1.05,
+    "shrink_paddle": false,
+    "speed_ups": {"four_hits": false, "twelve_hits": true, "orange_contact": false, "red_contact": true}
+  }
+}`)
+
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if got := DifficultyTag(cfg.Game); got != "easy" {
+		t.Errorf("DifficultyTag() = %q, want easy, rules = %+v", got, cfg.Game.Rules)
+	}
+}
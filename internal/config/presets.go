@@ -0,0 +1,175 @@
+package config
+
+import (
+	"breakout/internal/bricks"
+	"reflect"
+	"slices"
+	"strings"
+)
+
+// CustomDifficulty tags games whose values match no preset
+const CustomDifficulty = "custom"
+
+// Preset is a named difficulty, bundling game values with rule choices. The
+// levels are not part of a preset.
+type Preset struct {
+	Name        string
+	Title       string
+	Description string
+	Game        GameConfig
+}
+
+// speedUp is a rule that speeds the ball up by factor the first time trigger
+// fires
+func speedUp(name string, trigger Trigger, factor float32) Rule {
+	return Rule{
+		Name:    name,
+		Trigger: trigger,
+		Effect:  Effect{Action: ActionBallSpeed, Factor: factor},
+		Policy:  PolicyOnce,
+	}
+}
+
+// shrinkPaddle halves the paddle the first time the ball reaches the ceiling
+var shrinkPaddle = Rule{
+	Name:    "shrink_paddle",
+	Trigger: Trigger{Event: EventCeilingHit},
+	Effect:  Effect{Action: ActionPaddleWidth, Factor: 0.5},
+	Policy:  PolicyOnce,
+}
+
+// classicRules are the rules of the 1976 game: the ball speeds up by factor
+// after 4 and 12 hits and on first hitting each brick type marked speed_up,
+// the orange and red rows, and the paddle shrinks when the ball first reaches
+// the ceiling
+func classicRules(factor float32) []Rule {
+	rules := []Rule{
+		speedUp("four_hits", Trigger{Event: EventBrickHits, Count: 4}, factor),
+		speedUp("twelve_hits", Trigger{Event: EventBrickHits, Count: 12}, factor),
+	}
+	for _, id := range bricks.Default().IDs() {
+		if bricks.Default().MustLookup(id).SpeedUp {
+			rules = append(rules, speedUp(id+"_contact", Trigger{Event: EventBrickHit, Brick: id}, factor))
+		}
+	}
+	return append(rules, shrinkPaddle)
+}
+
+var normal = Preset{
+	Name:        "normal",
+	Title:       "Normal",
+	Description: "Three balls, the paddle shrinks at the ceiling",
+	Game: GameConfig{
+		Difficulty:      "normal",
+		BallBaseSpeed:   0.4,
+		PaddleBaseSpeed: 0.3,
+		PaddleEnglish:   0.15,
+		SpinTransfer:    0.0005,
+		SpinDecay:       1.5,
+		Serves:          3,
+		Rules:           classicRules(1.1),
+	},
+}
+
+// presets lists the built-in difficulties from easiest to hardest
+var presets = []Preset{
+	{
+		Name:        "easy",
+		Title:       "Easy",
+		Description: "Five slower balls and a paddle that never shrinks",
+		Game: GameConfig{
+			Difficulty:      "easy",
+			BallBaseSpeed:   0.3,
+			PaddleBaseSpeed: 0.35,
+			PaddleEnglish:   0.2,
+			SpinTransfer:    0.0005,
+			SpinDecay:       1.5,
+			Serves:          5,
+			Rules: []Rule{
+				speedUp("twelve_hits", Trigger{Event: EventBrickHits, Count: 12}, 1.05),
+				speedUp("red_contact", Trigger{Event: EventBrickHit, Brick: "red"}, 1.05),
+			},
+		},
+	},
+	normal,
+	{
+		Name:        "hard",
+		Title:       "Hard",
+		Description: "Two fast balls with bigger speed-ups",
+		Game: GameConfig{
+			Difficulty:      "hard",
+			BallBaseSpeed:   0.5,
+			PaddleBaseSpeed: 0.3,
+			PaddleEnglish:   0.1,
+			SpinTransfer:    0.0008,
+			SpinDecay:       1,
+			Serves:          2,
+			Rules:           classicRules(1.15),
+		},
+	},
+	{
+		Name:        "atari",
+		Title:       "Atari-authentic",
+		Description: "The 1976 rules: three balls, no English or spin",
+		Game: GameConfig{
+			Difficulty:      "atari",
+			BallBaseSpeed:   0.4,
+			PaddleBaseSpeed: 0.3,
+			Serves:          3,
+			Rules:           classicRules(1.1),
+		},
+	},
+}
+
+// Presets returns the built-in difficulties from easiest to hardest
+func Presets() []Preset {
+	return append([]Preset(nil), presets...)
+}
+
+// LookupPreset returns the difficulty with the given name
+func LookupPreset(name string) (Preset, bool) {
+	for _, p := range presets {
+		if p.Name == name {
+			return p, true
+		}
+	}
+	return Preset{}, false
+}
+
+// PresetNames returns the names of the built-in difficulties
+func PresetNames() []string {
+	names := make([]string, len(presets))
+	for i, p := range presets {
+		names[i] = p.Name
+	}
+	return names
+}
+
+// Apply returns g with every value the preset controls replaced by the
+// preset's
+func (p Preset) Apply(g GameConfig) GameConfig {
+	out := p.Game
+	out.Rules = slices.Clone(p.Game.Rules)
+	out.LevelsDir = g.LevelsDir
+	return out
+}
+
+// DifficultyTag names the difficulty a game with these values is played on:
+// the preset named by g.Difficulty if g matches it exactly on the built-in
+// levels, otherwise CustomDifficulty. Scores are only comparable between
+// games with the same tag.
+func DifficultyTag(g GameConfig) string {
+	if p, ok := LookupPreset(g.Difficulty); ok && g.LevelsDir == "" && reflect.DeepEqual(p.Apply(g), g) {
+		return p.Name
+	}
+	return CustomDifficulty
+}
+
+// presetControls reports whether a preset sets the value at path
+func presetControls(path string) bool {
+	switch path {
+	case "game.levels_dir", "game.difficulty":
+		return false
+	}
+	return strings.HasPrefix(path, "game.")
+}
@@ -0,0 +1,92 @@
+package config
+
+import (
+	"errors"
+	"reflect"
+	"slices"
+	"testing"
+)
+
+func TestPresetsAreValid(t *testing.T) {
+	for _, p := range Presets() {
+		t.Run(p.Name, func(t *testing.T) {
+			cfg := Default()
+			cfg.Game = p.Apply(cfg.Game)
+
+			if err := Validate(cfg); err != nil {
+				t.Errorf("Validate() = %v, want nil", err)
+			}
+			if got := DifficultyTag(cfg.Game); got != p.Name {
+				t.Errorf("DifficultyTag() = %q, want %q", got, p.Name)
+			}
+		})
+	}
+}
+
+func TestClassicRulesSpeedUpOnMarkedTypes(t *testing.T) {
+	var contacts []string
+	for _, r := range classicRules(1.1) {
+		if r.Trigger.Event == EventBrickHit {
+			contacts = append(contacts, r.Name+":"+r.Trigger.Brick)
+		}
+	}
+	if want := []string{"red_contact:red", "orange_contact:orange"}; !slices.Equal(contacts, want) {
+		t.Errorf("classicRules() brick_hit rules = %v, want %v", contacts, want)
+	}
+}
+
+func TestDifficultyTag(t *testing.T) {
+	if got := DifficultyTag(Default().Game); got != "normal" {
+		t.Errorf("DifficultyTag(Default()) = %q, want normal", got)
+	}
+
+	tuned := Default().Game
+	tuned.Serves = 99
+	if got := DifficultyTag(tuned); got != CustomDifficulty {
+		t.Errorf("DifficultyTag() of a tuned preset = %q, want %q", got, CustomDifficulty)
+	}
+
+	// Scores on other levels are not comparable
+	levels := Default().Game
+	levels.LevelsDir = "levels"
+	if got := DifficultyTag(levels); got != CustomDifficulty {
+		t.Errorf("DifficultyTag() on other levels = %q, want %q", got, CustomDifficulty)
+	}
+}
+
+func TestLoaderAppliesPreset(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	hard, _ := LookupPreset("hard")
+
+	eff, err := Loader{Flags: map[string]string{"game.difficulty": "hard"}}.Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if !reflect.DeepEqual(eff.Config.Game, hard.Apply(Default().Game)) {
+		t.Errorf("Load().Game = %+v, want the hard preset", eff.Config.Game)
+	}
+	if got := eff.Origins["game.serves"]; got != (Origin{LayerPreset, "hard"}) {
+		t.Errorf("Origins[game.serves] = %v, want preset hard", got)
+	}
+
+	// Layers above the preset still win, and the game is no longer "hard"
+	eff, err = Loader{
+		Environ: []string{"BREAKOUT_GAME_DIFFICULTY=hard"},
+		Flags:   map[string]string{"game.serves": "9"},
+	}.Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if eff.Config.Game.Serves != 9 || eff.Config.Game.BallBaseSpeed != hard.Game.BallBaseSpeed {
+		t.Errorf("Load().Game = %+v, want hard with 9 serves", eff.Config.Game)
+	}
+	if got := DifficultyTag(eff.Config.Game); got != CustomDifficulty {
+		t.Errorf("DifficultyTag() = %q, want %q", got, CustomDifficulty)
+	}
+
+	_, err = Loader{Flags: map[string]string{"game.difficulty": "nightmare"}}.Load()
+	var invalid *ValidationError
+	if !errors.As(err, &invalid) || invalid.Problems[0].Path != "game.difficulty" {
+		t.Errorf("Load() of an unknown difficulty error = %v, want a problem with game.difficulty", err)
+	}
+}
@@ -0,0 +1,86 @@
+package config
+
+import (
+	"os"
+	"time"
+)
+
+// LiveUpdate merges a new config into the one a game is running with. Values
+// that can only take effect when the game starts keep their current setting
+// and their paths are returned, everything else comes from next.
+func LiveUpdate(current, next Config) (Config, []string) {
+	restartOnly := []struct {
+		path    string
+		changed bool
+	}{
+		{"window.width", current.Window.Width != next.Window.Width},
+		{"window.height", current.Window.Height != next.Window.Height},
+		{"window.title", current.Window.Title != next.Window.Title},
+		{"window.target_fps", current.Window.TargetFPS != next.Window.TargetFPS},
+		{"window.resizable", current.Window.Resizable != next.Window.Resizable},
+		{"game.levels_dir", current.Game.LevelsDir != next.Game.LevelsDir},
+		{"audio.enabled", current.Audio.Enabled != next.Audio.Enabled},
+		{"audio.paddle_hit_sound_path", current.Audio.PaddleHitSoundPath != next.Audio.PaddleHitSoundPath},
+		{"audio.brick_hit_sound_path", current.Audio.BrickHitSoundPath != next.Audio.BrickHitSoundPath},
+	}
+
+	var restart []string
+	for _, field := range restartOnly {
+		if field.changed {
+			restart = append(restart, field.path)
+		}
+	}
+
+	applied := next
+	applied.Window = current.Window
+	applied.Window.Fullscreen = next.Window.Fullscreen
+	applied.Audio = current.Audio
+	applied.Audio.Volume = next.Audio.Volume
+	applied.Game.LevelsDir = current.Game.LevelsDir
+	return applied, restart
+}
+
+// Watcher notices changes to a config file by polling its modification time
+// and size. It needs no goroutines, so it can be checked from the main loop.
+type Watcher struct {
+	path      string
+	interval  time.Duration
+	lastCheck time.Time
+	modTime   time.Time
+	size      int64
+}
+
+// NewWatcher watches the file at path, checking it at most once per interval.
+// The file's current contents count as seen.
+func NewWatcher(path string, interval time.Duration) *Watcher {
+	w := &Watcher{path: path, interval: interval, lastCheck: time.Now()}
+	if info, err := os.Stat(path); err == nil {
+		w.modTime, w.size = info.ModTime(), info.Size()
+	}
+	return w
+}
+
+// Path returns the watched file's path
+func (w *Watcher) Path() string {
+	return w.path
+}
+
+// Changed reports whether the file changed since the last call. A file that
+// is missing or deleted counts as unchanged.
+func (w *Watcher) Changed() bool {
+	now := time.Now()
+	if now.Sub(w.lastCheck) < w.interval {
+		return false
+	}
+	w.lastCheck = now
+
+	info, err := os.Stat(w.path)
+	if err != nil {
+		return false
+	}
+	if info.ModTime().Equal(w.modTime) && info.Size() == w.size {
+		return false
+	}
+	w.modTime, w.size = info.ModTime(), info.Size()
+	return true
+}
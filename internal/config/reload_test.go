@@ -0,0 +1,68 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestLiveUpdate(t *testing.T) {
+	current := Default()
+	next := Default()
+	next.Game.BallBaseSpeed = 0.6
+	next.Game.Serves = 4
+	next.Game.LevelsDir = "levels"
+	next.Window.Width = 1024
+	next.Window.Fullscreen = true
+	next.Audio.Volume = 0.5
+
+	applied, restart := LiveUpdate(current, next)
+
+	if want := []string{"window.width", "game.levels_dir"}; !slices.Equal(restart, want) {
+		t.Errorf("LiveUpdate() restart = %v, want %v", restart, want)
+	}
+	if applied.Game.BallBaseSpeed != 0.6 || applied.Game.Serves != 4 {
+		t.Errorf("LiveUpdate() = %+v, want the new ball speed and serves", applied.Game)
+	}
+	if applied.Game.LevelsDir != current.Game.LevelsDir || applied.Window.Width != current.Window.Width {
+		t.Errorf("LiveUpdate() changed restart-only values: %+v", applied)
+	}
+	if !applied.Window.Fullscreen || applied.Audio.Volume != 0.5 {
+		t.Errorf("LiveUpdate() = %+v, %+v, want fullscreen and the new volume", applied.Window, applied.Audio)
+	}
+
+	if _, restart := LiveUpdate(current, current); restart != nil {
+		t.Errorf("LiveUpdate() of an unchanged config restart = %v, want none", restart)
+	}
+}
+
+func TestWatcher(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	writeFile(t, path, `{}`)
+	w := NewWatcher(path, 0)
+
+	if w.Changed() {
+		t.Fatal("Changed() = true for an untouched file")
+	}
+
+	writeFile(t, path, `{"game": {"ball_base_speed": 0.5}}`)
+	if !w.Changed() {
+		t.Fatal("Changed() = false after an edit")
+	}
+	if w.Changed() {
+		t.Error("Changed() reported the same edit twice")
+	}
+
+	if err := os.Remove(path); err != nil {
+		t.Fatal(err)
+	}
+	if w.Changed() {
+		t.Error("Changed() = true for a deleted file")
+	}
+
+	writeFile(t, path, `{"game": {"ball_base_speed": 0.6}}`)
+	if !w.Changed() {
+		t.Error("Changed() = false for a recreated file")
+	}
+}
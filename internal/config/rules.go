@@ -0,0 +1,119 @@
+package config
+
+import (
+	"breakout/internal/bricks"
+	"fmt"
+	"strings"
+)
+
+// Rule changes the game when something happens in it. A game mode's whole
+// progression, such as when the ball speeds up, is a list of rules.
+type Rule struct {
+	// Name identifies the rule in the game state and in messages
+	Name    string  `json:"name"`
+	Trigger Trigger `json:"trigger"`
+	Effect  Effect  `json:"effect"`
+	// Policy is PolicyOnce to fire at most once per game, or PolicyRepeat
+	// to fire every time the trigger does
+	Policy string `json:"policy"`
+}
+
+// Trigger events
+const (
+	// EventBrickHits fires once Count bricks have been hit in the game, or
+	// on every Count hits when repeated
+	EventBrickHits = "brick_hits"
+	// EventBrickHit fires when a brick of type Brick is hit
+	EventBrickHit = "brick_hit"
+	// EventCeilingHit fires when the ball hits the top wall
+	EventCeilingHit = "ceiling_hit"
+	// EventElapsed fires once Seconds of play have passed, or every Seconds
+	// when repeated. Time spent paused does not count.
+	EventElapsed = "elapsed"
+	// EventLevelStart fires when level Level starts, or any level if Level
+	// is 0
+	EventLevelStart = "level_start"
+)
+
+// Effect actions
+const (
+	// ActionBallSpeed multiplies the ball's speed by Factor
+	ActionBallSpeed = "ball_speed"
+	// ActionPaddleWidth multiplies the paddle's width by Factor
+	ActionPaddleWidth = "paddle_width"
+	// ActionExtraBall spawns Count extra balls for the player to serve
+	ActionExtraBall = "extra_ball"
+)
+
+// Rule policies
+const (
+	PolicyOnce   = "once"
+	PolicyRepeat = "repeat"
+)
+
+// Trigger is what makes a rule fire. Only the fields its event uses are set.
+type Trigger struct {
+	Event   string  `json:"event"`
+	Count   int32   `json:"count,omitempty"`
+	Brick   string  `json:"brick,omitempty"`
+	Seconds float32 `json:"seconds,omitempty"`
+	Level   int32   `json:"level,omitempty"`
+}
+
+// Effect is what a rule does when it fires. Only the fields its action uses
+// are set.
+type Effect struct {
+	Action string  `json:"action"`
+	Factor float32 `json:"factor,omitempty"`
+	Count  int32   `json:"count,omitempty"`
+}
+
+// rules checks every rule, reporting problems by their path within
+// game.rules, as in game.rules[2].trigger.count
+func (v *validator) rules(rules []Rule) {
+	seen := make(map[string]bool)
+	for i, r := range rules {
+		path := fmt.Sprintf("game.rules[%d]", i)
+
+		switch {
+		case r.Name == "":
+			v.add(path+".name", "must be set")
+		case seen[r.Name]:
+			v.add(path+".name", "%q is already the name of another rule", r.Name)
+		}
+		seen[r.Name] = true
+
+		t := r.Trigger
+		switch t.Event {
+		case EventBrickHits:
+			v.atLeast(path+".trigger.count", t.Count, 1)
+		case EventBrickHit:
+			if _, ok := bricks.Default().Lookup(t.Brick); !ok {
+				v.add(path+".trigger.brick", "unknown brick type %q, want one of %s", t.Brick, strings.Join(bricks.Default().IDs(), ", "))
+			}
+		case EventCeilingHit:
+		case EventElapsed:
+			v.positive(path+".trigger.seconds", t.Seconds)
+		case EventLevelStart:
+			v.atLeast(path+".trigger.level", t.Level, 0)
+		default:
+			v.add(path+".trigger.event", "unknown event %q, want one of %s", t.Event,
+				strings.Join([]string{EventBrickHits, EventBrickHit, EventCeilingHit, EventElapsed, EventLevelStart}, ", "))
+		}
+
+		e := r.Effect
+		switch e.Action {
+		case ActionBallSpeed, ActionPaddleWidth:
+			v.positive(path+".effect.factor", e.Factor)
+		case ActionExtraBall:
+			v.atLeast(path+".effect.count", e.Count, 1)
+		default:
+			v.add(path+".effect.action", "unknown action %q, want one of %s", e.Action,
+				strings.Join([]string{ActionBallSpeed, ActionPaddleWidth, ActionExtraBall}, ", "))
+		}
+
+		if r.Policy != PolicyOnce && r.Policy != PolicyRepeat {
+			v.add(path+".policy", "must be %q or %q, got %q", PolicyOnce, PolicyRepeat, r.Policy)
+		}
+	}
+}
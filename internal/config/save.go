@@ -0,0 +1,88 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// SetFileValues sets values in the config file at path, leaving everything
+// else in it as it is. Values map paths to values written the way flags take
+// them, as in "audio.volume": "0.5". A missing file is created, and a file of
+// an older version is upgraded first, keeping the original as path.bak.
+// Nothing is written if the result would not load.
+func SetFileValues(path string, values map[string]string) error {
+	original, err := os.ReadFile(path)
+	missing := errors.Is(err, fs.ErrNotExist)
+	if missing {
+		original, err = []byte("{}"), nil
+	}
+	if err != nil {
+		return err
+	}
+
+	var check Config
+	upgraded, version, err := decodeFile(original, &check)
+	if err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	var doc map[string]any
+	dec := json.NewDecoder(bytes.NewReader(upgraded))
+	dec.UseNumber()
+	if err := dec.Decode(&doc); err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+
+	byPath := make(map[string]field)
+	for _, f := range fields(&check) {
+		byPath[f.path] = f
+	}
+	for _, key := range slices.Sorted(maps.Keys(values)) {
+		f, ok := byPath[key]
+		if !ok {
+			return fmt.Errorf("unknown config value %s", key)
+		}
+		if err := f.set(values[key]); err != nil {
+			return &ValidationError{Problems: []FieldError{{Path: key, Reason: err.Error()}}}
+		}
+		setDocument(doc, key, toDocument(f.value.Interface()))
+	}
+
+	data, err := formatDocument(doc)
+	if err != nil {
+		return err
+	}
+	if _, err := Parse(data); err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	if version < Version && !missing {
+		return upgradeFile(path, original, data)
+	}
+	return replaceFile(path, data)
+}
+
+// setDocument sets the value at path in a decoded config file, adding the
+// sections on the way if the file has none
+func setDocument(doc map[string]any, path string, value any) {
+	keys := strings.Split(path, ".")
+	for _, key := range keys[:len(keys)-1] {
+		section, ok := doc[key].(map[string]any)
+		if !ok {
+			section = make(map[string]any)
+			doc[key] = section
+		}
+		doc = section
+	}
+	doc[keys[len(keys)-1]] = value
+}
@@ -0,0 +1,102 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestSetFileValuesKeepsTheRest(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	writeFile(t, path, `{"version": 4, "game": {"serves": 4}, "window": {"title": "Mine"}}`)
+
+	if err := SetFileValues(path, map[string]string{"audio.volume": "0.5", "controls.left": "left"}); err != nil {
+		t.Fatalf("SetFileValues() error = %v", err)
+	}
+
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if cfg.Audio.Volume != 0.5 || cfg.Controls.Left != "left" {
+		t.Errorf("Volume, Left = %v, %q, want 0.5, left", cfg.Audio.Volume, cfg.Controls.Left)
+	}
+	if cfg.Game.Serves != 4 || cfg.Window.Title != "Mine" {
+		t.Errorf("Serves, Title = %d, %q, want the file's 4, Mine", cfg.Game.Serves, cfg.Window.Title)
+	}
+
+	// Only the values set are written, so the preset still fills the rest
+	set, _ := fileKeys(mustRead(t, path))
+	if want := "audio.volume controls.left game.serves window.title"; strings.Join(set, " ") != want {
+		t.Errorf("file sets %v, want %s", set, want)
+	}
+}
+
+func TestSetFileValuesCreatesFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "breakout", "config.json")
+
+	if err := SetFileValues(path, map[string]string{"game.difficulty": "hard"}); err != nil {
+		t.Fatalf("SetFileValues() error = %v", err)
+	}
+	want := "{\n  \"version\": 4,\n  \"game\": {\n    \"difficulty\": \"hard\"\n  }\n}\n"
+	if got := string(mustRead(t, path)); got != want {
+		t.Errorf("file = %q, want %q", got, want)
+	}
+}
+
+func TestSetFileValuesUpgrades(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	writeFile(t, path, historical[0].file)
+
+	if err := SetFileValues(path, map[string]string{"window.fullscreen": "true"}); err != nil {
+		t.Fatalf("SetFileValues() error = %v", err)
+	}
+	if backup := mustRead(t, path+".bak"); string(backup) != historical[0].file {
+		t.Errorf("backup = %q, want the original file", backup)
+	}
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	want := historical[0].want()
+	want.Window.Fullscreen = true
+	if !reflect.DeepEqual(cfg, want) {
+		t.Errorf("Load() = %+v, want the upgraded file with fullscreen on", cfg)
+	}
+}
+
+func TestSetFileValuesRejects(t *testing.T) {
+	tests := []struct {
+		name   string
+		values map[string]string
+	}{
+		{"Unknown value", map[string]string{"audio.loudness": "1"}},
+		{"Wrong type", map[string]string{"controls.paddle_speed": "fast"}},
+		{"Invalid value", map[string]string{"controls.right": "a"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "config.json")
+			writeFile(t, path, `{"version": 4}`)
+
+			if err := SetFileValues(path, tt.values); err == nil {
+				t.Error("SetFileValues() error = nil, want an error")
+			}
+			if got := string(mustRead(t, path)); got != `{"version": 4}` {
+				t.Errorf("file = %q after a rejected change, want it untouched", got)
+			}
+		})
+	}
+}
+
+func mustRead(t *testing.T, path string) []byte {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
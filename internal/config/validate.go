@@ -0,0 +1,136 @@
+package config
+
+import (
+	"fmt"
+	"math"
+	"strings"
+)
+
+// FieldError is a problem with a single config value
+type FieldError struct {
+	// Path is the value's JSON path, such as "game.ball_base_speed"
+	Path   string
+	Reason string
+}
+
+func (e FieldError) Error() string {
+	return e.Path + ": " + e.Reason
+}
+
+// ValidationError lists every problem found in a config
+type ValidationError struct {
+	Problems []FieldError
+}
+
+func (e *ValidationError) Error() string {
+	if len(e.Problems) == 1 {
+		return e.Problems[0].Error()
+	}
+
+	msgs := make([]string, len(e.Problems))
+	for i, p := range e.Problems {
+		msgs[i] = p.Error()
+	}
+	return fmt.Sprintf("%d problems: %s", len(e.Problems), strings.Join(msgs, "; "))
+}
+
+// Validate checks that a config describes a playable game. It reports every
+// problem at once as a *ValidationError, or returns nil.
+func Validate(cfg Config) error {
+	v := &validator{}
+
+	w := cfg.Window
+	v.atLeast("window.width", w.Width, 1)
+	v.atLeast("window.height", w.Height, 1)
+	v.atLeast("window.target_fps", w.TargetFPS, 1)
+
+	g := cfg.Game
+	v.positive("game.ball_base_speed", g.BallBaseSpeed)
+	v.positive("game.paddle_base_speed", g.PaddleBaseSpeed)
+	v.notNegative("game.paddle_english", g.PaddleEnglish)
+	v.finite("game.spin_transfer", g.SpinTransfer)
+	v.notNegative("game.spin_decay", g.SpinDecay)
+	if _, ok := LookupPreset(g.Difficulty); !ok && g.Difficulty != CustomDifficulty {
+		v.add("game.difficulty", "unknown difficulty %q, want one of %s or %s",
+			g.Difficulty, strings.Join(PresetNames(), ", "), CustomDifficulty)
+	}
+	v.atLeast("game.serves", g.Serves, 1)
+	v.rules(g.Rules)
+
+	a := cfg.Audio
+	if a.Enabled {
+		v.notEmpty("audio.paddle_hit_sound_path", a.PaddleHitSoundPath)
+		v.notEmpty("audio.brick_hit_sound_path", a.BrickHitSoundPath)
+	}
+	if !(a.Volume >= 0 && a.Volume <= 1) {
+		v.add("audio.volume", "must be from 0 to 1, got %v", a.Volume)
+	}
+
+	c := cfg.Controls
+	bound := make(map[string]string)
+	for _, k := range []struct{ path, name string }{
+		{"controls.left", c.Left},
+		{"controls.right", c.Right},
+		{"controls.faster", c.Faster},
+		{"controls.slower", c.Slower},
+		{"controls.serve", c.Serve},
+		{"controls.restart", c.Restart},
+	} {
+		switch _, ok := KeyCode(k.name); {
+		case !ok:
+			v.add(k.path, "unknown key %q", k.name)
+		case bound[k.name] != "":
+			v.add(k.path, "%q is already bound to %s", k.name, bound[k.name])
+		default:
+			bound[k.name] = k.path
+		}
+	}
+	if c.PaddleSpeed < MinPaddleSpeed || c.PaddleSpeed > MaxPaddleSpeed {
+		v.add("controls.paddle_speed", "must be from %d to %d, got %d", MinPaddleSpeed, MaxPaddleSpeed, c.PaddleSpeed)
+	}
+
+	if len(v.problems) == 0 {
+		return nil
+	}
+	return &ValidationError{Problems: v.problems}
+}
+
+// validator collects problems in the order they are found
+type validator struct {
+	problems []FieldError
+}
+
+func (v *validator) add(path, format string, args ...any) {
+	v.problems = append(v.problems, FieldError{Path: path, Reason: fmt.Sprintf(format, args...)})
+}
+
+func (v *validator) atLeast(path string, value, least int32) {
+	if value < least {
+		v.add(path, "must be at least %d, got %d", least, value)
+	}
+}
+
+func (v *validator) positive(path string, value float32) {
+	// Written so that NaN fails too
+	if !(value > 0) || math.IsInf(float64(value), 0) {
+		v.add(path, "must be a positive number, got %v", value)
+	}
+}
+
+func (v *validator) notNegative(path string, value float32) {
+	if !(value >= 0) || math.IsInf(float64(value), 0) {
+		v.add(path, "must not be negative, got %v", value)
+	}
+}
+
+func (v *validator) finite(path string, value float32) {
+	if math.IsNaN(float64(value)) || math.IsInf(float64(value), 0) {
+		v.add(path, "must be a finite number, got %v", value)
+	}
+}
+
+func (v *validator) notEmpty(path, value string) {
+	if value == "" {
+		v.add(path, "must be set while audio is enabled")
+	}
+}
@@ -0,0 +1,130 @@
+package config
+
+import (
+	"errors"
+	"math"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func problemPaths(t *testing.T, err error) []string {
+	t.Helper()
+
+	var invalid *ValidationError
+	if !errors.As(err, &invalid) {
+		t.Fatalf("error = %v, want a *ValidationError", err)
+	}
+	paths := make([]string, len(invalid.Problems))
+	for i, p := range invalid.Problems {
+		paths[i] = p.Path
+	}
+	return paths
+}
+
+func TestDefaultIsValid(t *testing.T) {
+	if err := Validate(Default()); err != nil {
+		t.Errorf("Validate(Default()) = %v, want nil", err)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(*Config)
+		want   []string
+	}{
+		{"Negative ball speed", func(c *Config) { c.Game.BallBaseSpeed = -0.4 }, []string{"game.ball_base_speed"}},
+		{"NaN paddle speed", func(c *Config) { c.Game.PaddleBaseSpeed = float32(math.NaN()) }, []string{"game.paddle_base_speed"}},
+		{"Zero speed-up factor", func(c *Config) { c.Game.Rules[0].Effect.Factor = 0 }, []string{"game.rules[0].effect.factor"}},
+		{"Unknown brick type", func(c *Config) { c.Game.Rules[2].Trigger.Brick = "purple" }, []string{"game.rules[2].trigger.brick"}},
+		{"Unknown event", func(c *Config) { c.Game.Rules[1].Trigger.Event = "paddle_hit" }, []string{"game.rules[1].trigger.event"}},
+		{"Duplicate rule name", func(c *Config) { c.Game.Rules[1].Name = c.Game.Rules[0].Name }, []string{"game.rules[1].name"}},
+		{"Unknown policy", func(c *Config) { c.Game.Rules[4].Policy = "twice" }, []string{"game.rules[4].policy"}},
+		{"No rules is allowed", func(c *Config) { c.Game.Rules = nil }, nil},
+		{
+			"Extra ball every 30 seconds",
+			func(c *Config) {
+				c.Game.Rules = append(c.Game.Rules, Rule{
+					Name:    "bonus",
+					Trigger: Trigger{Event: EventElapsed, Seconds: 30},
+					Effect:  Effect{Action: ActionExtraBall, Count: 1},
+					Policy:  PolicyRepeat,
+				})
+			},
+			nil,
+		},
+		{"No balls", func(c *Config) { c.Game.Serves = 0 }, []string{"game.serves"}},
+		{"Negative spin decay", func(c *Config) { c.Game.SpinDecay = -1 }, []string{"game.spin_decay"}},
+		{"Negative spin transfer is allowed", func(c *Config) { c.Game.SpinTransfer = -0.001 }, nil},
+		{"Zero frame rate", func(c *Config) { c.Window.TargetFPS = 0 }, []string{"window.target_fps"}},
+		{"Small window is allowed", func(c *Config) { c.Window.Width = 70; c.Window.Height = 90 }, nil},
+		{"Missing sound", func(c *Config) { c.Audio.BrickHitSoundPath = "" }, []string{"audio.brick_hit_sound_path"}},
+		{"Loud volume", func(c *Config) { c.Audio.Volume = 1.5 }, []string{"audio.volume"}},
+		{"Unknown key", func(c *Config) { c.Controls.Left = "mouse4" }, []string{"controls.left"}},
+		{"Key bound twice", func(c *Config) { c.Controls.Serve = "a" }, []string{"controls.serve"}},
+		{"Rebound keys", func(c *Config) { c.Controls.Left = "left"; c.Controls.Right = "right" }, nil},
+		{"Paddle speed too high", func(c *Config) { c.Controls.PaddleSpeed = 6 }, []string{"controls.paddle_speed"}},
+		{"Missing sound with audio off", func(c *Config) { c.Audio.Enabled = false; c.Audio.BrickHitSoundPath = "" }, nil},
+		{
+			"Every problem at once",
+			func(c *Config) {
+				c.Window.Width = 0
+				c.Game.Serves = 0
+				c.Game.BallBaseSpeed = -1
+			},
+			[]string{"window.width", "game.ball_base_speed", "game.serves"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := Default()
+			tt.modify(&cfg)
+
+			err := Validate(cfg)
+			if tt.want == nil {
+				if err != nil {
+					t.Errorf("Validate() = %v, want nil", err)
+				}
+				return
+			}
+			if got := problemPaths(t, err); !slices.Equal(got, tt.want) {
+				t.Errorf("Validate() problems at %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseReportsPosition(t *testing.T) {
+	t.Run("Wrong type", func(t *testing.T) {
+		_, err := Parse([]byte("{\n  \"game\": {\n    \"serves\": \"3\"\n  }\n}"))
+		if got := problemPaths(t, err); !slices.Equal(got, []string{"game.serves"}) {
+			t.Errorf("Parse() problems at %v, want [game.serves]", got)
+		}
+		if !strings.Contains(err.Error(), "line 3") {
+			t.Errorf("Parse() error = %q, want it to name line 3", err)
+		}
+	})
+
+	t.Run("Wrong type in a rule", func(t *testing.T) {
+		_, err := Parse([]byte(`{"version": 3, "game": {"rules": [{"trigger": {"count": "4"}}]}}`))
+		if got := problemPaths(t, err); !slices.Equal(got, []string{"game.rules[0].trigger.count"}) {
+			t.Errorf("Parse() problems at %v, want [game.rules[0].trigger.count]", got)
+		}
+	})
+
+	t.Run("Syntax error", func(t *testing.T) {
+		_, err := Parse([]byte("{\n  \"game\": {\n    \"serves\": 3,,\n  }\n}"))
+		if err == nil || !strings.Contains(err.Error(), "line 3, column 17") {
+			t.Errorf("Parse() error = %v, want it to point at line 3, column 17", err)
+		}
+	})
+
+	t.Run("Invalid value", func(t *testing.T) {
+		_, err := Parse([]byte(`{"game": {"serves": 0}}`))
+		if got := problemPaths(t, err); !slices.Equal(got, []string{"game.serves"}) {
+			t.Errorf("Parse() problems at %v, want [game.serves]", got)
+		}
+	})
+}
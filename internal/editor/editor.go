@@ -0,0 +1,293 @@
+// Package editor edits level files. The Editor holds the level being edited
+// and every change to it, with undo and redo; the screen in screen.go drives
+// it from the mouse and keyboard.
+package editor
+
+import (
+	"breakout/internal/bricks"
+	"breakout/internal/config"
+	"breakout/internal/levels"
+	"breakout/internal/types"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+)
+
+// Size of the grid a new level starts with, the classic wall's
+const (
+	NewLevelColumns = 14
+	NewLevelRows    = 8
+)
+
+const (
+	// undoLimit is how many changes can be undone
+	undoLimit = 200
+	// minBallSpeed keeps the ball moving in a level
+	minBallSpeed = 0.1
+)
+
+// Editor is a level being edited and the history of changes to it
+type Editor struct {
+	path   string
+	level  *levels.Level
+	cursor types.GridPos
+	// brush is the brick type Place and Recolour put down
+	brush *bricks.Type
+
+	// undo and redo hold the level as it was before each change, and after
+	// each undone one
+	undo, redo []*levels.Level
+	// stroke is the level as it was when the current stroke began, or nil
+	// outside a stroke
+	stroke        *levels.Level
+	strokeChanged bool
+	dirty         bool
+	// saved is the level as it was last saved, or nil before the first save
+	saved *levels.Level
+}
+
+// Open edits the level file at path. A file that does not exist yet starts
+// as a blank level named after it, and is created on the first save.
+func Open(path string) (*Editor, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		name := strings.TrimSuffix(filepath.Base(path), levels.Extension)
+		return New(path, levels.Blank(name, NewLevelColumns, NewLevelRows)), nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	l, err := levels.Parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return New(path, l), nil
+}
+
+// New edits a copy of level, to be saved to path
+func New(path string, level *levels.Level) *Editor {
+	return &Editor{
+		path:  path,
+		level: level.Clone(),
+		brush: bricks.Default().MustLookup(bricks.Default().IDs()[0]),
+	}
+}
+
+// Path returns the file the level is saved to
+func (e *Editor) Path() string {
+	return e.path
+}
+
+// Level returns the level being edited. Change it through the editor only.
+func (e *Editor) Level() *levels.Level {
+	return e.level
+}
+
+// Dirty reports whether the level has changed since it was opened or saved
+func (e *Editor) Dirty() bool {
+	return e.dirty
+}
+
+// Saved returns the level as it was last saved, or nil if it has not been
+// saved since it was opened
+func (e *Editor) Saved() *levels.Level {
+	return e.saved
+}
+
+// Cursor returns the cell the keyboard edits
+func (e *Editor) Cursor() types.GridPos {
+	return e.cursor
+}
+
+// SetCursor moves the cursor to pos, kept on the grid
+func (e *Editor) SetCursor(pos types.GridPos) {
+	cols, rows := e.level.Size()
+	e.cursor = types.GridPos{
+		Col: max(0, min(cols-1, pos.Col)),
+		Row: max(0, min(rows-1, pos.Row)),
+	}
+}
+
+// MoveCursor moves the cursor by the given number of columns and rows
+func (e *Editor) MoveCursor(cols, rows int32) {
+	e.SetCursor(types.GridPos{Col: e.cursor.Col + cols, Row: e.cursor.Row + rows})
+}
+
+// Brush returns the brick type Place puts down
+func (e *Editor) Brush() *bricks.Type {
+	return e.brush
+}
+
+// SetBrush chooses the brick type Place puts down
+func (e *Editor) SetBrush(t *bricks.Type) {
+	e.brush = t
+}
+
+// Place puts a brick of the brush's type at pos, replacing any brick there
+func (e *Editor) Place(pos types.GridPos) {
+	e.setCell(pos, e.brush, false)
+}
+
+// Erase removes the brick at pos
+func (e *Editor) Erase(pos types.GridPos) {
+	e.setCell(pos, nil, false)
+}
+
+// Recolour changes the brick at pos to the brush's type. Empty cells stay
+// empty, so a stroke across a pattern keeps its gaps.
+func (e *Editor) Recolour(pos types.GridPos) {
+	e.setCell(pos, e.brush, true)
+}
+
+func (e *Editor) setCell(pos types.GridPos, t *bricks.Type, bricksOnly bool) {
+	if cols, rows := e.level.Size(); pos.Col < 0 || pos.Row < 0 || pos.Col >= cols || pos.Row >= rows {
+		return
+	}
+	if bricksOnly && e.level.Grid[pos.Row][pos.Col] == nil {
+		return
+	}
+	e.change(func(l *levels.Level) {
+		l.Grid[pos.Row][pos.Col] = t
+	})
+}
+
+// Resize changes the grid to cols by rows, within what fits on the playfield.
+// Bricks outside the new grid are removed, and new cells are empty.
+func (e *Editor) Resize(cols, rows int32) {
+	cols = max(1, min(config.MaxBrickColumns, cols))
+	rows = max(1, min(config.MaxBrickRows, rows))
+	e.change(func(l *levels.Level) {
+		grid := levels.Blank("", cols, rows).Grid
+		for row := range min(rows, int32(len(l.Grid))) {
+			copy(grid[row], l.Grid[row])
+		}
+		l.Grid = grid
+	})
+	e.SetCursor(e.cursor)
+}
+
+// SetName renames the level
+func (e *Editor) SetName(name string) {
+	e.change(func(l *levels.Level) {
+		l.Name = name
+	})
+}
+
+// SetBallSpeed sets the level's ball speed, at least minBallSpeed
+func (e *Editor) SetBallSpeed(speed float32) {
+	e.change(func(l *levels.Level) {
+		l.BallSpeed = max(minBallSpeed, speed)
+	})
+}
+
+// SetPar sets the level's par score, at least 0
+func (e *Editor) SetPar(par int32) {
+	e.change(func(l *levels.Level) {
+		l.Par = max(0, par)
+	})
+}
+
+// SetBackground sets the level's background from a colour written as
+// #rrggbb
+func (e *Editor) SetBackground(hex string) error {
+	c, err := bricks.ParseColor(hex)
+	if err != nil {
+		return err
+	}
+	e.change(func(l *levels.Level) {
+		l.Background = c
+	})
+	return nil
+}
+
+// BeginStroke starts a stroke: every change until EndStroke, such as painting
+// while the mouse is dragged, is undone in one step
+func (e *Editor) BeginStroke() {
+	if e.stroke == nil {
+		e.stroke = e.level.Clone()
+		e.strokeChanged = false
+	}
+}
+
+// EndStroke finishes the current stroke
+func (e *Editor) EndStroke() {
+	if e.stroke != nil && e.strokeChanged {
+		e.pushUndo(e.stroke)
+	}
+	e.stroke = nil
+}
+
+// change applies edit to the level, recording the level as it was for undo
+// if that changed it
+func (e *Editor) change(edit func(l *levels.Level)) {
+	before := e.level.Clone()
+	edit(e.level)
+	if reflect.DeepEqual(before, e.level) {
+		return
+	}
+	e.dirty = true
+	e.redo = nil
+	if e.stroke != nil {
+		e.strokeChanged = true
+		return
+	}
+	e.pushUndo(before)
+}
+
+func (e *Editor) pushUndo(l *levels.Level) {
+	e.undo = append(e.undo, l)
+	if len(e.undo) > undoLimit {
+		e.undo = e.undo[1:]
+	}
+}
+
+// Undo reverts the last change, reporting false if there is none
+func (e *Editor) Undo() bool {
+	e.EndStroke()
+	if len(e.undo) == 0 {
+		return false
+	}
+	e.redo = append(e.redo, e.level)
+	e.level = e.undo[len(e.undo)-1]
+	e.undo = e.undo[:len(e.undo)-1]
+	e.dirty = true
+	e.SetCursor(e.cursor)
+	return true
+}
+
+// Redo applies the last undone change again, reporting false if there is
+// none
+func (e *Editor) Redo() bool {
+	e.EndStroke()
+	if len(e.redo) == 0 {
+		return false
+	}
+	e.undo = append(e.undo, e.level)
+	e.level = e.redo[len(e.redo)-1]
+	e.redo = e.redo[:len(e.redo)-1]
+	e.dirty = true
+	e.SetCursor(e.cursor)
+	return true
+}
+
+// Save writes the level to its file. A level the game could not load, such
+// as one without bricks, is not saved.
+func (e *Editor) Save() error {
+	data := e.level.Format()
+	if _, err := levels.Parse(data); err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(e.path), 0o755); err != nil {
+		return err
+	}
+	if err := os.WriteFile(e.path, data, 0o644); err != nil {
+		return err
+	}
+	e.dirty = false
+	e.saved = e.level.Clone()
+	return nil
+}
@@ -0,0 +1,206 @@
+package editor
+
+import (
+	"breakout/internal/bricks"
+	"breakout/internal/levels"
+	"breakout/internal/types"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestPlaceEraseRecolour(t *testing.T) {
+	e := New("test.level", levels.Blank("Test", 3, 2))
+	red, yellow := bricks.Default().MustLookup("red"), bricks.Default().MustLookup("yellow")
+
+	e.SetBrush(red)
+	e.Place(types.GridPos{Col: 0, Row: 0})
+	e.Place(types.GridPos{Col: 1, Row: 0})
+	e.Erase(types.GridPos{Col: 1, Row: 0})
+	if got := string(grid(e)); got != "R..\n...\n" {
+		t.Errorf("grid after placing and erasing = %q", got)
+	}
+
+	// Recolouring changes bricks, but leaves empty cells empty
+	e.SetBrush(yellow)
+	e.Recolour(types.GridPos{Col: 0, Row: 0})
+	e.Recolour(types.GridPos{Col: 2, Row: 1})
+	if got := string(grid(e)); got != "Y..\n...\n" {
+		t.Errorf("grid after recolouring = %q", got)
+	}
+
+	// Cells off the grid are ignored
+	e.Place(types.GridPos{Col: 3, Row: 0})
+	e.Place(types.GridPos{Col: 0, Row: -1})
+	if got := string(grid(e)); got != "Y..\n...\n" {
+		t.Errorf("grid after placing off the grid = %q", got)
+	}
+}
+
+func TestUndoRedo(t *testing.T) {
+	e := New("test.level", levels.Blank("Test", 3, 1))
+	original := e.Level().Clone()
+
+	e.Place(types.GridPos{Col: 0})
+	e.SetName("Renamed")
+	placed := e.Level().Clone()
+
+	if !e.Undo() || e.Level().Name != "Test" {
+		t.Fatalf("Undo() left the name %q, want Test", e.Level().Name)
+	}
+	if !e.Undo() || !reflect.DeepEqual(e.Level(), original) {
+		t.Fatalf("two Undo() calls = %+v, want the original level", e.Level())
+	}
+	if e.Undo() {
+		t.Error("Undo() with nothing to undo = true")
+	}
+
+	e.Redo()
+	if !e.Redo() || !reflect.DeepEqual(e.Level(), placed) {
+		t.Fatalf("two Redo() calls = %+v, want both changes back", e.Level())
+	}
+	if e.Redo() {
+		t.Error("Redo() with nothing to redo = true")
+	}
+
+	// A new change forgets what was undone
+	e.Undo()
+	e.SetPar(50)
+	if e.Redo() {
+		t.Error("Redo() after a new change = true")
+	}
+}
+
+func TestStrokeUndoesAtOnce(t *testing.T) {
+	e := New("test.level", levels.Blank("Test", 4, 1))
+
+	e.BeginStroke()
+	for col := range int32(4) {
+		e.Place(types.GridPos{Col: col})
+	}
+	e.EndStroke()
+	// A stroke that changes nothing leaves nothing to undo
+	e.BeginStroke()
+	e.Place(types.GridPos{Col: 0})
+	e.EndStroke()
+
+	if got := string(grid(e)); got != "RRRR\n" {
+		t.Fatalf("grid after the stroke = %q", got)
+	}
+	if !e.Undo() || string(grid(e)) != "....\n" {
+		t.Errorf("Undo() after a stroke = %q, want the whole stroke undone", grid(e))
+	}
+	if e.Undo() {
+		t.Error("Undo() = true, want the stroke to be a single change")
+	}
+}
+
+func TestResize(t *testing.T) {
+	e := New("test.level", mustParse(t, "{}\nRO\nGY\n"))
+	e.SetCursor(types.GridPos{Col: 1, Row: 1})
+
+	e.Resize(1, 3)
+	if got := string(grid(e)); got != "R\nG\n.\n" {
+		t.Errorf("grid after resizing = %q, want the bricks that still fit", got)
+	}
+	if e.Cursor() != (types.GridPos{Col: 0, Row: 1}) {
+		t.Errorf("Cursor() = %+v, want it moved onto the grid", e.Cursor())
+	}
+
+	e.Resize(0, 1000)
+	if cols, rows := e.Level().Size(); cols != 1 || rows != 47 {
+		t.Errorf("Size() = %d, %d, want the grid kept between 1 and what fits", cols, rows)
+	}
+}
+
+func TestSettings(t *testing.T) {
+	e := New("test.level", levels.Blank("Test", 1, 1))
+
+	e.SetBallSpeed(-1)
+	e.SetPar(-10)
+	if l := e.Level(); l.BallSpeed != minBallSpeed || l.Par != 0 {
+		t.Errorf("BallSpeed, Par = %v, %d, want %v, 0", l.BallSpeed, l.Par, minBallSpeed)
+	}
+
+	if err := e.SetBackground("#102030"); err != nil {
+		t.Fatalf("SetBackground() error = %v", err)
+	}
+	if got := bricks.FormatColor(e.Level().Background); got != "#102030" {
+		t.Errorf("Background = %s, want #102030", got)
+	}
+	if err := e.SetBackground("blue"); err == nil {
+		t.Error("SetBackground(\"blue\") error = nil")
+	}
+}
+
+func TestOpenAndSave(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "levels", "04-new.level")
+
+	// A new file starts blank, named after the file
+	e, err := Open(path)
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	if cols, rows := e.Level().Size(); e.Level().Name != "04-new" || cols != NewLevelColumns || rows != NewLevelRows {
+		t.Errorf("Open() = %q, %d x %d, want a blank 04-new level", e.Level().Name, cols, rows)
+	}
+
+	// A level without bricks could not be played, so it is not saved
+	if err := e.Save(); err == nil || !strings.Contains(err.Error(), "no bricks") {
+		t.Errorf("Save() of an empty level error = %v, want no bricks", err)
+	}
+	if e.Saved() != nil {
+		t.Errorf("Saved() after a failed save = %+v, want nil", e.Saved())
+	}
+
+	e.Place(types.GridPos{Col: 2, Row: 1})
+	e.SetPar(30)
+	if !e.Dirty() {
+		t.Error("Dirty() after changes = false")
+	}
+	if err := e.Save(); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	if e.Dirty() {
+		t.Error("Dirty() after saving = true")
+	}
+	if !reflect.DeepEqual(e.Saved(), e.Level()) {
+		t.Errorf("Saved() = %+v, want the saved level", e.Saved())
+	}
+
+	reopened, err := Open(path)
+	if err != nil {
+		t.Fatalf("Open() of the saved file error = %v", err)
+	}
+	if !reflect.DeepEqual(reopened.Level(), e.Level()) {
+		t.Errorf("reopened level = %+v, want %+v", reopened.Level(), e.Level())
+	}
+	if got, err := levels.Load(filepath.Dir(path)); err != nil || len(got) != 1 {
+		t.Errorf("Load() of the saved level = %v, %v, want it to load", got, err)
+	}
+
+	bad := filepath.Join(t.TempDir(), "bad.level")
+	if err := os.WriteFile(bad, []byte("{}\nZ\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Open(bad); err == nil || !strings.Contains(err.Error(), bad+": line 2") {
+		t.Errorf("Open() of a broken file error = %v, want it to point at the line", err)
+	}
+}
+
+// grid returns the grid part of the edited level's file
+func grid(e *Editor) []byte {
+	data := e.Level().Format()
+	return data[strings.Index(string(data), "}\n")+2:]
+}
+
+func mustParse(t *testing.T, data string) *levels.Level {
+	t.Helper()
+	l, err := levels.Parse([]byte(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return l
+}
@@ -0,0 +1,502 @@
+package editor
+
+import (
+	"breakout/internal/bricks"
+	"breakout/internal/config"
+	"breakout/internal/game"
+	"breakout/internal/input"
+	"breakout/internal/levels"
+	"breakout/internal/renderer"
+	"breakout/internal/types"
+	"fmt"
+	"math"
+	"path/filepath"
+	"strconv"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
+
+const (
+	// backKey leaves the settings panel or a test game, and quits the editor
+	backKey = rl.KeyEscape
+	// settingsKey opens the panel with the level's settings
+	settingsKey = rl.KeyTab
+	// playKey test-plays the level
+	playKey = rl.KeyF5
+
+	// maxNameLength keeps a level's name on one line of the screen
+	maxNameLength = 40
+
+	paletteX          = 20
+	paletteY          = config.PlayfieldHeight - 110
+	paletteSwatchSize = 20
+	paletteItemWidth  = 80
+)
+
+// help is shown at the bottom of the screen while the grid is being edited
+var help = []string{
+	"Click: place  Shift+click: recolour  Right click: erase  1-9: brick type",
+	"Arrows: move  Space: place  C: recolour  Delete: erase",
+	"Ctrl+Z/Y: undo/redo  Ctrl+S: save  Tab: settings  F5: play  Esc: quit",
+}
+
+// field is a line on the settings panel
+type field struct {
+	label string
+	show  func(l *levels.Level) string
+	// step changes the setting in direction dir, -1 or 1
+	step func(e *Editor, dir int)
+	// set changes the setting to typed text. Settings with one are typed in
+	// rather than stepped.
+	set func(e *Editor, text string) error
+}
+
+// fieldBack closes the settings panel
+const fieldBack = "Back"
+
+var fields = []field{
+	{
+		label: "Name",
+		show:  func(l *levels.Level) string { return l.Name },
+		set: func(e *Editor, text string) error {
+			e.SetName(text)
+			return nil
+		},
+	},
+	{
+		label: "Ball speed",
+		show:  func(l *levels.Level) string { return strconv.FormatFloat(float64(l.BallSpeed), 'f', 1, 32) },
+		step: func(e *Editor, dir int) {
+			tenths := math.Round(float64(e.Level().BallSpeed)*10) + float64(dir)
+			e.SetBallSpeed(float32(tenths / 10))
+		},
+	},
+	{
+		label: "Par",
+		show:  func(l *levels.Level) string { return strconv.Itoa(int(l.Par)) },
+		step: func(e *Editor, dir int) {
+			e.SetPar(e.Level().Par + int32(dir)*10)
+		},
+	},
+	{
+		label: "Background",
+		show:  func(l *levels.Level) string { return bricks.FormatColor(l.Background) },
+		set: func(e *Editor, text string) error {
+			return e.SetBackground(text)
+		},
+	},
+	{
+		label: "Columns",
+		show:  func(l *levels.Level) string { cols, _ := l.Size(); return strconv.Itoa(int(cols)) },
+		step: func(e *Editor, dir int) {
+			cols, rows := e.Level().Size()
+			e.Resize(cols+int32(dir), rows)
+		},
+	},
+	{
+		label: "Rows",
+		show:  func(l *levels.Level) string { _, rows := l.Size(); return strconv.Itoa(int(rows)) },
+		step: func(e *Editor, dir int) {
+			cols, rows := e.Level().Size()
+			e.Resize(cols, rows+int32(dir))
+		},
+	},
+	{label: fieldBack},
+}
+
+// Screen runs an Editor in the open window. Like the game's settings screen,
+// it reads the mouse and keyboard directly and runs on rendered frames.
+type Screen struct {
+	editor   *Editor
+	cfg      config.Config
+	keyboard *input.Keyboard
+	renderer *renderer.Renderer
+
+	// play is the game trying out the level, or nil while editing
+	play *game.Game
+
+	// panel is set while the settings panel is open, and typing while text
+	// is being typed into its selected field
+	panel    bool
+	selected int
+	typing   bool
+	text     string
+
+	// message replaces the help line until the next one
+	message string
+	// quitArmed is set once quitting with unsaved changes has been warned of
+	quitArmed bool
+	done      bool
+}
+
+// NewScreen runs e in the open window. Test games are played with cfg, read
+// from keyboard.
+func NewScreen(e *Editor, cfg config.Config, keyboard *input.Keyboard) *Screen {
+	// The edited level replaces the configured levels, which need not load
+	cfg.Game.LevelsDir = ""
+	return &Screen{
+		editor:   e,
+		cfg:      cfg,
+		keyboard: keyboard,
+		renderer: renderer.New(),
+	}
+}
+
+// Done reports whether the designer has quit the editor
+func (s *Screen) Done() bool {
+	return s.done
+}
+
+// Close ends any test game
+func (s *Screen) Close() {
+	if s.play != nil {
+		s.play.Cleanup()
+		s.play = nil
+	}
+}
+
+// Update handles a frame of input
+func (s *Screen) Update(frameTime float32) {
+	switch {
+	case s.play != nil:
+		s.updatePlay(frameTime)
+	case s.panel:
+		s.updatePanel()
+	default:
+		s.updateGrid()
+	}
+}
+
+// updatePlay runs the test game for a frame
+func (s *Screen) updatePlay(frameTime float32) {
+	if rl.IsKeyPressed(backKey) {
+		s.Close()
+		return
+	}
+	s.keyboard.Capture()
+	s.play.Update(frameTime)
+}
+
+// startPlay tries out the level as it is now
+func (s *Screen) startPlay() {
+	// Play what would be saved, so a level that could not be saved is not
+	// played either
+	if _, err := levels.Parse(s.editor.Level().Format()); err != nil {
+		s.message = "Cannot play the level: " + err.Error()
+		return
+	}
+	g, err := game.New(s.cfg, s.keyboard)
+	if err != nil {
+		s.message = err.Error()
+		return
+	}
+	g.PlayLevels([]*levels.Level{s.editor.Level().Clone()})
+	g.Initialize()
+	s.play = g
+}
+
+// updateGrid handles a frame of editing the brick grid
+func (s *Screen) updateGrid() {
+	e := s.editor
+	ctrl := rl.IsKeyDown(rl.KeyLeftControl) || rl.IsKeyDown(rl.KeyRightControl)
+	shift := rl.IsKeyDown(rl.KeyLeftShift) || rl.IsKeyDown(rl.KeyRightShift)
+
+	// Any input clears the last message, and anything but a second Escape
+	// keeps the editor open
+	if key := rl.GetKeyPressed(); key != 0 || rl.IsMouseButtonPressed(rl.MouseButtonLeft) || rl.IsMouseButtonPressed(rl.MouseButtonRight) {
+		s.message = ""
+		if key != backKey {
+			s.quitArmed = false
+		}
+	}
+
+	s.updateMouse(shift)
+
+	switch {
+	case ctrl && rl.IsKeyPressed(rl.KeyZ) && shift, ctrl && rl.IsKeyPressed(rl.KeyY):
+		if !e.Redo() {
+			s.message = "Nothing to redo"
+		}
+	case ctrl && rl.IsKeyPressed(rl.KeyZ):
+		if !e.Undo() {
+			s.message = "Nothing to undo"
+		}
+	case ctrl && rl.IsKeyPressed(rl.KeyS):
+		s.save()
+	case rl.IsKeyPressed(backKey):
+		s.quit()
+	case rl.IsKeyPressed(settingsKey):
+		s.panel = true
+		s.selected = 0
+	case rl.IsKeyPressed(playKey):
+		s.startPlay()
+	case rl.IsKeyPressed(rl.KeyLeft):
+		e.MoveCursor(-1, 0)
+	case rl.IsKeyPressed(rl.KeyRight):
+		e.MoveCursor(1, 0)
+	case rl.IsKeyPressed(rl.KeyUp):
+		e.MoveCursor(0, -1)
+	case rl.IsKeyPressed(rl.KeyDown):
+		e.MoveCursor(0, 1)
+	case rl.IsKeyPressed(rl.KeySpace) || rl.IsKeyPressed(rl.KeyEnter):
+		e.Place(e.Cursor())
+	case rl.IsKeyPressed(rl.KeyC):
+		e.Recolour(e.Cursor())
+	case rl.IsKeyPressed(rl.KeyBackspace) || rl.IsKeyPressed(rl.KeyDelete):
+		e.Erase(e.Cursor())
+	}
+
+	for i, id := range paletteIDs() {
+		if rl.IsKeyPressed(rl.KeyOne + int32(i)) {
+			e.SetBrush(bricks.Default().MustLookup(id))
+		}
+	}
+}
+
+// updateMouse paints with the mouse. Each press and drag is one stroke, so it
+// is undone in one step.
+func (s *Screen) updateMouse(shift bool) {
+	e := s.editor
+	// The mouse is in playfield coordinates, see renderer.Screen
+	mouse := rl.GetMousePosition()
+	cell, onGrid := e.Level().Layout().CellAt(types.Vector2{X: mouse.X, Y: mouse.Y})
+	if onGrid && rl.GetMouseDelta() != (rl.Vector2{}) {
+		e.SetCursor(cell)
+	}
+
+	if rl.IsMouseButtonPressed(rl.MouseButtonLeft) {
+		if id, ok := paletteAt(mouse); ok {
+			e.SetBrush(bricks.Default().MustLookup(id))
+			return
+		}
+	}
+
+	left, right := rl.IsMouseButtonDown(rl.MouseButtonLeft), rl.IsMouseButtonDown(rl.MouseButtonRight)
+	if !left && !right {
+		e.EndStroke()
+		return
+	}
+	if !onGrid {
+		return
+	}
+	e.BeginStroke()
+	switch {
+	case right:
+		e.Erase(cell)
+	case shift:
+		e.Recolour(cell)
+	default:
+		e.Place(cell)
+	}
+}
+
+// save saves the level, showing how that went
+func (s *Screen) save() {
+	if err := s.editor.Save(); err != nil {
+		s.message = "Not saved: " + err.Error()
+		return
+	}
+	s.message = "Saved " + s.editor.Path()
+	s.quitArmed = false
+}
+
+// quit leaves the editor, warning once first if there are unsaved changes
+func (s *Screen) quit() {
+	if s.editor.Dirty() && !s.quitArmed {
+		s.quitArmed = true
+		s.message = "The level has unsaved changes: press Escape again to quit without saving, or Ctrl+S to save"
+		return
+	}
+	s.done = true
+}
+
+// updatePanel handles a frame of the settings panel
+func (s *Screen) updatePanel() {
+	if s.typing {
+		s.updateTyping()
+		return
+	}
+
+	// The mouse is in playfield coordinates, see renderer.Screen
+	hovered, onItem := s.renderer.MenuItemAt(len(fields), rl.GetMousePosition().Y)
+	if onItem && rl.GetMouseDelta() != (rl.Vector2{}) {
+		s.selected = hovered
+	}
+
+	item := fields[s.selected]
+	switch {
+	case rl.IsKeyPressed(backKey) || rl.IsKeyPressed(settingsKey):
+		s.panel = false
+	case rl.IsKeyPressed(rl.KeyUp):
+		s.selected = (s.selected + len(fields) - 1) % len(fields)
+		s.message = ""
+	case rl.IsKeyPressed(rl.KeyDown):
+		s.selected = (s.selected + 1) % len(fields)
+		s.message = ""
+	case rl.IsKeyPressed(rl.KeyLeft) && item.step != nil:
+		item.step(s.editor, -1)
+	case rl.IsKeyPressed(rl.KeyRight) && item.step != nil:
+		item.step(s.editor, 1)
+	case onItem && rl.IsMouseButtonPressed(rl.MouseButtonLeft):
+		s.selected = hovered
+		s.activate()
+	case rl.IsKeyPressed(rl.KeyEnter):
+		s.activate()
+	}
+}
+
+// activate does what Enter does on the selected field
+func (s *Screen) activate() {
+	item := fields[s.selected]
+	switch {
+	case item.label == fieldBack:
+		s.panel = false
+	case item.set != nil:
+		s.typing = true
+		s.text = item.show(s.editor.Level())
+		s.message = ""
+	case item.step != nil:
+		item.step(s.editor, 1)
+	}
+}
+
+// updateTyping edits the text of the selected field for a frame
+func (s *Screen) updateTyping() {
+	for c := rl.GetCharPressed(); c != 0; c = rl.GetCharPressed() {
+		if c >= ' ' && c <= '~' && len(s.text) < maxNameLength {
+			s.text += string(rune(c))
+		}
+	}
+
+	switch {
+	case rl.IsKeyPressed(backKey):
+		s.typing = false
+	case rl.IsKeyPressed(rl.KeyBackspace) && s.text != "":
+		s.text = s.text[:len(s.text)-1]
+	case rl.IsKeyPressed(rl.KeyEnter):
+		if err := fields[s.selected].set(s.editor, s.text); err != nil {
+			s.message = err.Error()
+			return
+		}
+		s.typing = false
+	}
+}
+
+// Draw renders the editor, or the test game while one is being played
+func (s *Screen) Draw() {
+	if s.play != nil {
+		s.play.Draw()
+		s.renderer.DrawNotice("Trying out the level: press Escape to go back to the editor")
+		return
+	}
+
+	e := s.editor
+	level := e.Level()
+	s.renderer.DrawBackground(level.Background)
+
+	// Empty cells are outlined, so the grid can be seen and clicked
+	layout := level.Layout()
+	cols, rows := level.Size()
+	for row := range rows {
+		for col := range cols {
+			if level.Grid[row][col] == nil {
+				bounds := layout.Bounds(types.GridPos{Col: col, Row: row})
+				rl.DrawRectangleLinesEx(bounds.ToRaylib(), 1, rl.Fade(rl.Gray, 0.4))
+			}
+		}
+	}
+	for _, brick := range level.Bricks() {
+		brick.Draw()
+	}
+	cursor := layout.Bounds(e.Cursor()).ToRaylib()
+	cursor.X, cursor.Y, cursor.Width, cursor.Height = cursor.X-2, cursor.Y-2, cursor.Width+4, cursor.Height+4
+	rl.DrawRectangleLinesEx(cursor, 2, rl.RayWhite)
+
+	title := fmt.Sprintf("%s  %d x %d", filepath.Base(e.Path()), cols, rows)
+	if e.Dirty() {
+		title += "  (unsaved)"
+	}
+	rl.DrawText(title, 20, 20, 20, rl.RayWhite)
+	s.renderer.DrawLevel(1, level.Name, level.Par)
+	s.drawPalette()
+
+	if s.panel {
+		s.drawPanel()
+		return
+	}
+	if s.message != "" {
+		s.renderer.DrawNotice(s.message)
+		return
+	}
+	y := int32(config.PlayfieldHeight - 20*len(help) - 10)
+	for _, line := range help {
+		rl.DrawText(line, 20, y, 16, rl.LightGray)
+		y += 20
+	}
+}
+
+// drawPalette renders the brick types that can be placed, marking the brush
+func (s *Screen) drawPalette() {
+	for i, id := range paletteIDs() {
+		t := bricks.Default().MustLookup(id)
+		x := int32(paletteX + i*paletteItemWidth)
+		rl.DrawRectangle(x, paletteY, paletteSwatchSize, paletteSwatchSize, t.Color)
+		if t == s.editor.Brush() {
+			rl.DrawRectangleLines(x-3, paletteY-3, paletteSwatchSize+6, paletteSwatchSize+6, rl.RayWhite)
+		}
+		label := fmt.Sprintf("%d %s", i+1, t.ID)
+		rl.DrawText(label, x+paletteSwatchSize+6, paletteY+2, 16, rl.LightGray)
+	}
+}
+
+// drawPanel renders the settings panel over the dimmed grid
+func (s *Screen) drawPanel() {
+	rl.DrawRectangle(0, 0, config.PlayfieldWidth, config.PlayfieldHeight, rl.Fade(rl.Black, 0.8))
+
+	level := s.editor.Level()
+	lines := make([]string, len(fields))
+	for i, item := range fields {
+		lines[i] = item.label
+		if item.show != nil {
+			value := item.show(level)
+			if s.typing && i == s.selected {
+				value = s.text + "_"
+			}
+			lines[i] += ": " + value
+		}
+	}
+
+	help := s.message
+	if help == "" {
+		switch item := fields[s.selected]; {
+		case s.typing:
+			help = "Type the new value, Enter to set it or Escape to cancel"
+		case item.set != nil:
+			help = "Enter to change"
+		case item.step != nil:
+			help = "Left and right to change"
+		}
+	}
+	s.renderer.DrawMenu("Level Settings", lines, s.selected, help)
+}
+
+// paletteIDs returns the brick types that can be chosen by number, at most
+// nine
+func paletteIDs() []string {
+	ids := bricks.Default().IDs()
+	return ids[:min(len(ids), 9)]
+}
+
+// paletteAt returns the brick type whose palette entry is at playfield point
+// p
+func paletteAt(p rl.Vector2) (string, bool) {
+	if p.Y < paletteY || p.Y >= paletteY+paletteSwatchSize || p.X < paletteX {
+		return "", false
+	}
+	i := int(p.X-paletteX) / paletteItemWidth
+	ids := paletteIDs()
+	if i >= len(ids) {
+		return "", false
+	}
+	return ids[i], true
+}
@@ -1,34 +1,69 @@
 package entities
 
 import (
+	"breakout/internal/config"
 	"breakout/internal/types"
+	"encoding/json"
 	"math"
 
 	rl "github.com/gen2brain/raylib-go/raylib"
 )
 
 const (
-	BallSize           = 10
-	BallBaseSpeed      = 0.4
-	BallSpeedIncrement = 1.1
-	WindowWidth        = 768
-	WindowHeight       = 1024
+	BallSize   = 10
+	BallRadius = BallSize / 2
+
+	// paddleTopNormalY is how far up a paddle contact normal must point to
+	// count as a hit on the paddle's top, which steers the ball by where it
+	// landed. Hits on the sides, lower corners and underside bounce off the
+	// surface instead.
+	paddleTopNormalY = -0.7
+
+	// maxBounceAngle is the steepest angle from vertical the ball may leave
+	// the paddle's top at, 75 degrees
+	maxBounceAngle = 5 * math.Pi / 12
 )
 
-// Ball represents the game ball
+// Spin controls how the paddle's own motion carries over into the ball
+type Spin struct {
+	// English is the fraction of the paddle's horizontal velocity added to
+	// the ball's on a top hit
+	English float32
+	// Transfer is the spin, in radians per second of curve, gained per pixel
+	// per second of paddle velocity
+	Transfer float32
+	// Decay is the fraction of spin lost per second
+	Decay float32
+}
+
+// Ball represents the game ball. Its velocity is stored as a fraction of the
+// playfield's size per second.
 type Ball struct {
-	pos      types.Vector2
-	velocity rl.Vector2
+	pos        types.Vector2
+	prevPos    types.Vector2
+	velocity   rl.Vector2
+	spin       float32
+	hitCeiling bool
+	width      float32
+	height     float32
 }
 
-// NewBall creates a new ball at the center of the screen
-func NewBall() *Ball {
+// NewBall creates a new ball at the center of the screen, moving at the
+// configured base speed
+func NewBall(cfg config.Config) *Ball {
+	pos := types.Vector2{X: config.PlayfieldWidth / 2, Y: config.PlayfieldHeight / 2}
+	speed := cfg.Game.BallBaseSpeed
+	return NewBallAt(pos, rl.Vector2{X: speed, Y: speed})
+}
+
+// NewBallAt creates a ball at the given position and velocity
+func NewBallAt(pos types.Vector2, velocity rl.Vector2) *Ball {
 	return &Ball{
-		pos: types.Vector2{X: WindowWidth / 2, Y: WindowHeight / 2},
-		velocity: rl.Vector2{
-			X: BallBaseSpeed,
-			Y: BallBaseSpeed,
-		},
+		pos:      pos,
+		prevPos:  pos,
+		velocity: velocity,
+		width:    config.PlayfieldWidth,
+		height:   config.PlayfieldHeight,
 	}
 }
 
@@ -45,94 +80,201 @@ func (b *Ball) Velocity() rl.Vector2 {
 // GetBounds returns the collision bounds
 func (b *Ball) GetBounds() types.Rectangle {
 	return types.Rectangle{
-		X:      float32(b.pos.X),
-		Y:      float32(b.pos.Y),
+		X:      b.pos.X,
+		Y:      b.pos.Y,
 		Width:  BallSize,
 		Height: BallSize,
 	}
 }
 
+// Circle returns the ball's collision shape
+func (b *Ball) Circle() types.Circle {
+	return types.Circle{
+		Center: types.Vector2{X: b.pos.X + BallRadius, Y: b.pos.Y + BallRadius},
+		Radius: BallRadius,
+	}
+}
+
 // Draw renders the ball
 func (b *Ball) Draw() {
-	rl.DrawRectangle(b.pos.X, b.pos.Y, BallSize, BallSize, rl.RayWhite)
+	b.DrawInterpolated(1)
+}
+
+// DrawInterpolated renders the ball blended between its previous and current
+// tick positions, alpha being the fraction of a tick elapsed since the last one
+func (b *Ball) DrawInterpolated(alpha float32) {
+	x := types.Lerp(b.prevPos.X, b.pos.X, alpha)
+	y := types.Lerp(b.prevPos.Y, b.pos.Y, alpha)
+	rl.DrawCircle(types.Snap(x+BallRadius), types.Snap(y+BallRadius), BallRadius, rl.RayWhite)
+}
+
+// SavePrevious records the current position as the start of the next tick
+func (b *Ball) SavePrevious() {
+	b.prevPos = b.pos
 }
 
 // Update moves the ball and handles wall collisions
 func (b *Ball) Update(deltaTime float32) {
-	b.pos.X += int32(b.velocity.X * deltaTime * float32(WindowWidth))
-	b.pos.Y += int32(b.velocity.Y * deltaTime * float32(WindowHeight))
+	b.Move(b.Displacement(deltaTime))
+	b.BounceOffWalls()
+}
+
+// Displacement returns how far the ball travels in the given time at its
+// current velocity
+func (b *Ball) Displacement(deltaTime float32) types.Vector2 {
+	return types.Vector2{
+		X: b.velocity.X * deltaTime * b.width,
+		Y: b.velocity.Y * deltaTime * b.height,
+	}
+}
+
+// Move translates the ball by delta without any collision handling
+func (b *Ball) Move(delta types.Vector2) {
+	b.pos.X += delta.X
+	b.pos.Y += delta.Y
+}
 
-	// Handle wall collisions
-	if b.pos.X <= 0 || b.pos.X+BallSize >= WindowWidth {
+// BounceOffWalls reflects the ball off the side and top walls. It only reflects
+// when moving into a wall, so a ball that overshot is not flipped back and forth.
+func (b *Ball) BounceOffWalls() {
+	if b.pos.X <= 0 && b.velocity.X < 0 {
+		b.pos.X = -b.pos.X
+		b.velocity.X = -b.velocity.X
+	} else if b.pos.X+BallSize >= b.width && b.velocity.X > 0 {
+		b.pos.X -= 2 * (b.pos.X + BallSize - b.width)
 		b.velocity.X = -b.velocity.X
 	}
 
-	if b.pos.Y <= 0 {
+	b.hitCeiling = b.pos.Y <= 0 && b.velocity.Y < 0
+	if b.hitCeiling {
+		b.pos.Y = -b.pos.Y
 		b.velocity.Y = -b.velocity.Y
 	}
 }
 
-// ReflectOffBrick reflects the ball off a brick
-func (b *Ball) ReflectOffBrick(brick *Brick) {
-	axis := b.getCollisionAxis(brick)
-	switch axis {
-	case CollisionAxisVertical:
-		b.velocity.Y = -b.velocity.Y
-	case CollisionAxisHorizontal:
-		b.velocity.X = -b.velocity.X
+// HitCeiling reports whether the last wall check bounced the ball off the top wall
+func (b *Ball) HitCeiling() bool {
+	return b.hitCeiling
+}
+
+// Reflect mirrors the ball's velocity about a unit surface normal. A ball
+// already moving away from the surface is left alone.
+func (b *Ball) Reflect(normal types.Vector2) {
+	// Velocity is stored relative to the playfield size, the normal is in
+	// pixels, so reflect in pixel space to keep the angles true
+	v := b.pixelVelocity()
+
+	d := v.X*normal.X + v.Y*normal.Y
+	if d >= 0 {
+		return
+	}
+	b.setPixelVelocity(types.Vector2{X: v.X - 2*d*normal.X, Y: v.Y - 2*d*normal.Y})
+}
+
+// Spin returns the ball's spin in radians per second of curve. Positive spin
+// turns the ball clockwise on screen.
+func (b *Ball) Spin() float32 {
+	return b.spin
+}
+
+// Curve turns the ball's direction by its spin over the given time, keeping
+// its speed, and lets the spin decay
+func (b *Ball) Curve(deltaTime float32, decay float32) {
+	if b.spin == 0 {
+		return
 	}
+
+	angle := float64(b.spin * deltaTime)
+	sin, cos := float32(math.Sin(angle)), float32(math.Cos(angle))
+	v := b.pixelVelocity()
+	b.setPixelVelocity(types.Vector2{X: v.X*cos - v.Y*sin, Y: v.X*sin + v.Y*cos})
+
+	b.spin *= max(0, 1-decay*deltaTime)
+}
+
+func (b *Ball) pixelVelocity() types.Vector2 {
+	return types.Vector2{X: b.velocity.X * b.width, Y: b.velocity.Y * b.height}
+}
+
+func (b *Ball) setPixelVelocity(v types.Vector2) {
+	b.velocity.X = v.X / b.width
+	b.velocity.Y = v.Y / b.height
+}
+
+// BounceOffPaddle handles a contact with the paddle whose surface normal at the
+// contact point is normal. Hits on the top are steered by ReflectOffPaddle and
+// pick up the paddle's motion through spin, anything else reflects off the
+// surface. A ball already moving away from the paddle is left alone so it
+// cannot be caught by the same contact twice. Returns whether the ball bounced.
+func (b *Ball) BounceOffPaddle(paddle *PlayerPaddle, normal types.Vector2, spin Spin) bool {
+	v := b.pixelVelocity()
+	if v.X*normal.X+v.Y*normal.Y >= 0 {
+		return false
+	}
+
+	if normal.Y <= paddleTopNormalY {
+		b.ReflectOffPaddle(paddle)
+		b.applyEnglish(paddle.Velocity(), spin)
+	} else {
+		b.Reflect(normal)
+	}
+	return true
+}
+
+// applyEnglish adds part of the paddle's velocity to the ball as tangential
+// velocity and spin. The ball keeps its speed, so English only changes its
+// direction, and never beyond the steepest paddle bounce angle.
+func (b *Ball) applyEnglish(paddleVelocity float32, spin Spin) {
+	b.spin += spin.Transfer * paddleVelocity
+	if spin.English == 0 || paddleVelocity == 0 {
+		return
+	}
+
+	v := b.pixelVelocity()
+	speed := float32(math.Hypot(float64(v.X), float64(v.Y)))
+	v.X += spin.English * paddleVelocity
+
+	angle := math.Atan2(float64(v.X), float64(-v.Y))
+	angle = max(-maxBounceAngle, min(maxBounceAngle, angle))
+	b.setPixelVelocity(types.Vector2{
+		X: speed * float32(math.Sin(angle)),
+		Y: -speed * float32(math.Cos(angle)),
+	})
 }
 
 // ReflectOffPaddle reflects the ball off the paddle with angle variation
 func (b *Ball) ReflectOffPaddle(paddle *PlayerPaddle) {
-	paddleCenterX := paddle.X() * float32(WindowWidth)
-	ballCenterX := float32(b.pos.X) + BallSize/2
+	bounds := paddle.GetBounds()
+	paddleCenterX := bounds.X + bounds.Width/2
+	ballCenterX := b.pos.X + BallSize/2
 	relativeIntersectX := (ballCenterX - paddleCenterX) / (paddle.Width() / 2)
-	bounceAngle := relativeIntersectX * (5 * math.Pi / 12) // Max bounce angle of 75 degrees
-	
-	speed := float32(math.Sqrt(float64(b.velocity.X*b.velocity.X + b.velocity.Y*b.velocity.Y)))
+	// Landing on a top corner can put the centre past the paddle's edge
+	relativeIntersectX = max(-1, min(1, relativeIntersectX))
+	bounceAngle := relativeIntersectX * maxBounceAngle
+
+	speed := b.Speed()
 	b.velocity.X = speed * float32(math.Sin(float64(bounceAngle)))
 	b.velocity.Y = -speed * float32(math.Cos(float64(bounceAngle)))
 }
 
+// MarshalJSON encodes the ball's position and velocity
+func (b *Ball) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		X    float32 `json:"x"`
+		Y    float32 `json:"y"`
+		VX   float32 `json:"vx"`
+		VY   float32 `json:"vy"`
+		Spin float32 `json:"spin"`
+	}{b.pos.X, b.pos.Y, b.velocity.X, b.velocity.Y, b.spin})
+}
+
+// Speed returns the magnitude of the ball's velocity
+func (b *Ball) Speed() float32 {
+	return float32(math.Sqrt(float64(b.velocity.X*b.velocity.X + b.velocity.Y*b.velocity.Y)))
+}
+
 // IncreaseSpeed multiplies the current speed by the given factor
 func (b *Ball) IncreaseSpeed(factor float32) {
 	b.velocity.X *= factor
 	b.velocity.Y *= factor
 }
-
-type CollisionAxis int
-
-const (
-	CollisionAxisVertical CollisionAxis = iota
-	CollisionAxisHorizontal
-)
-
-func (b *Ball) getCollisionAxis(other types.Collidable) CollisionAxis {
-	bBounds := b.GetBounds()
-	oBounds := other.GetBounds()
-	
-	bCenter := rl.Vector2{
-		X: bBounds.X + bBounds.Width/2,
-		Y: bBounds.Y + bBounds.Height/2,
-	}
-	oCenter := rl.Vector2{
-		X: oBounds.X + oBounds.Width/2,
-		Y: oBounds.Y + oBounds.Height/2,
-	}
-	
-	absDiff := rl.Vector2{
-		X: float32(math.Abs(float64(bCenter.X - oCenter.X))),
-		Y: float32(math.Abs(float64(bCenter.Y - oCenter.Y))),
-	}
-	
-	halfWidths := (bBounds.Width + oBounds.Width) / 2
-	halfHeights := (bBounds.Height + oBounds.Height) / 2
-	overlapX := halfWidths - absDiff.X
-	overlapY := halfHeights - absDiff.Y
-	
-	if overlapX < overlapY {
-		return CollisionAxisHorizontal
-	}
-	return CollisionAxisVertical
-}
\ No newline at end of file
@@ -0,0 +1,212 @@
+package entities
+
+import (
+	"breakout/internal/config"
+	"breakout/internal/types"
+	"math"
+	"testing"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
+
+var testConfig = config.Default()
+
+func TestBallDistanceIndependentOfFrameRate(t *testing.T) {
+	// Half a second keeps the ball clear of every wall from its start position
+	const seconds = 0.5
+
+	reference := NewBall(testConfig)
+	start := reference.Position()
+	reference.Update(seconds)
+	want := reference.Position()
+
+	for _, fps := range []int{30, 60, 144, 240, 1000, 5000} {
+		ball := NewBall(testConfig)
+		steps := int(seconds * float32(fps))
+		for i := 0; i < steps; i++ {
+			ball.Update(1 / float32(fps))
+		}
+
+		// Float rounding may drift by a small fraction of a pixel
+		got := ball.Position()
+		if math.Abs(float64(got.X-want.X)) > 0.1 || math.Abs(float64(got.Y-want.Y)) > 0.1 {
+			t.Errorf("At %d FPS the ball moved from %v to %v, want %v", fps, start, got, want)
+		}
+	}
+}
+
+func TestBallKeepsSlowAxis(t *testing.T) {
+	ball := NewBall(testConfig)
+	ball.velocity.X = 0.001
+	start := ball.Position()
+
+	// Each step moves the ball far less than a pixel along X
+	for i := 0; i < 1000; i++ {
+		ball.Update(1.0 / 1000)
+	}
+
+	if moved := ball.Position().X - start.X; moved <= 0 {
+		t.Errorf("Ball moved %v pixels along the slow axis, want a positive distance", moved)
+	}
+}
+
+func TestBallReflectsOffSideWalls(t *testing.T) {
+	ball := NewBall(testConfig)
+	ball.pos.X = ball.width - BallSize - 1
+	ball.velocity.X = 0.4
+
+	ball.Update(0.01)
+
+	if ball.velocity.X >= 0 {
+		t.Fatalf("Ball velocity X = %v after hitting the right wall, want negative", ball.velocity.X)
+	}
+	if ball.pos.X+BallSize > ball.width {
+		t.Errorf("Ball right edge at %v is past the wall at %v", ball.pos.X+BallSize, ball.width)
+	}
+
+	// A second step must not flip the ball back into the wall
+	ball.Update(0.001)
+	if ball.velocity.X >= 0 {
+		t.Errorf("Ball reflected twice off the same wall")
+	}
+}
+
+func TestBallReportsCeilingHit(t *testing.T) {
+	ball := NewBall(testConfig)
+	ball.pos.Y = 1
+	ball.velocity.Y = -0.4
+
+	ball.Update(0.01)
+	if !ball.HitCeiling() {
+		t.Fatal("HitCeiling() = false after bouncing off the top wall")
+	}
+
+	ball.Update(0.01)
+	if ball.HitCeiling() {
+		t.Error("HitCeiling() = true on the update after the bounce")
+	}
+}
+
+func TestBallReflect(t *testing.T) {
+	tests := []struct {
+		name     string
+		velocity rl.Vector2
+		normal   types.Vector2
+		want     rl.Vector2
+	}{
+		{"Flat face", rl.Vector2{X: 0.3, Y: -0.4}, types.Vector2{Y: 1}, rl.Vector2{X: 0.3, Y: 0.4}},
+		// In pixels per second (0, -512) reflects to (491.52, 143.36)
+		{"Corner", rl.Vector2{Y: -0.5}, types.Vector2{X: 0.6, Y: 0.8}, rl.Vector2{X: 0.64, Y: 0.14}},
+		{"Moving away", rl.Vector2{X: 0.3, Y: 0.4}, types.Vector2{Y: 1}, rl.Vector2{X: 0.3, Y: 0.4}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ball := NewBallAt(types.Vector2{}, tt.velocity)
+			ball.Reflect(tt.normal)
+
+			got := ball.Velocity()
+			if math.Abs(float64(got.X-tt.want.X)) > 1e-5 || math.Abs(float64(got.Y-tt.want.Y)) > 1e-5 {
+				t.Errorf("Reflect() velocity = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBallPaddleEnglish(t *testing.T) {
+	tests := []struct {
+		name           string
+		spin           Spin
+		paddleVelocity float32
+		wantDirection  float32 // sign of the ball's horizontal velocity
+		wantSpin       float32
+	}{
+		{"Classic ignores paddle motion", Spin{}, 400, 0, 0},
+		{"Still paddle", Spin{English: 0.5, Transfer: 0.001}, 0, 0, 0},
+		{"Paddle moving right", Spin{English: 0.5, Transfer: 0.001}, 400, 1, 0.4},
+		{"Paddle moving left", Spin{English: 0.5, Transfer: 0.001}, -400, -1, -0.4},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			paddle := NewPlayerPaddle(testConfig, 0.5, nil)
+			paddle.velocity = tt.paddleVelocity
+
+			// Falling straight onto the middle of the paddle
+			ball := NewBallAt(above(paddle), rl.Vector2{Y: 0.4})
+			speed := pixelSpeed(ball)
+
+			if !ball.BounceOffPaddle(paddle, types.Vector2{Y: -1}, tt.spin) {
+				t.Fatal("BounceOffPaddle() = false for a ball falling onto the paddle")
+			}
+
+			v := ball.Velocity()
+			if v.Y >= 0 {
+				t.Errorf("Ball velocity Y = %v, want upwards", v.Y)
+			}
+			if got := sign(v.X); got != tt.wantDirection {
+				t.Errorf("Ball velocity X = %v, want sign %v", v.X, tt.wantDirection)
+			}
+			if diff := pixelSpeed(ball) - speed; math.Abs(float64(diff)) > 1e-3 {
+				t.Errorf("Ball speed changed by %v, English should only change direction", diff)
+			}
+			if math.Abs(float64(ball.Spin()-tt.wantSpin)) > 1e-5 {
+				t.Errorf("Spin() = %v, want %v", ball.Spin(), tt.wantSpin)
+			}
+		})
+	}
+}
+
+func TestBallEnglishRespectsMaxAngle(t *testing.T) {
+	paddle := NewPlayerPaddle(testConfig, 0.5, nil)
+	paddle.velocity = 100000
+
+	ball := NewBallAt(above(paddle), rl.Vector2{Y: 0.4})
+	ball.BounceOffPaddle(paddle, types.Vector2{Y: -1}, Spin{English: 1})
+
+	v := ball.pixelVelocity()
+	angle := math.Atan2(float64(v.X), float64(-v.Y))
+	if angle > maxBounceAngle+1e-5 {
+		t.Errorf("Ball left the paddle at %v radians, want at most %v", angle, maxBounceAngle)
+	}
+}
+
+func TestBallCurve(t *testing.T) {
+	ball := NewBallAt(types.Vector2{}, rl.Vector2{Y: -0.4})
+	ball.spin = 1
+	speed := pixelSpeed(ball)
+
+	ball.Curve(0.1, 2)
+
+	if vx := ball.Velocity().X; vx <= 0 {
+		t.Errorf("Positive spin left velocity X at %v, want the ball curving right", vx)
+	}
+	if diff := pixelSpeed(ball) - speed; math.Abs(float64(diff)) > 1e-3 {
+		t.Errorf("Curve changed the ball's speed by %v", diff)
+	}
+	if spin := ball.Spin(); math.Abs(float64(spin-0.8)) > 1e-5 {
+		t.Errorf("Spin() = %v after decaying for 0.1s at 2 per second, want 0.8", spin)
+	}
+}
+
+// above returns the position of a ball resting on the middle of the paddle
+func above(paddle *PlayerPaddle) types.Vector2 {
+	bounds := paddle.GetBounds()
+	return types.Vector2{X: bounds.X + bounds.Width/2 - BallRadius, Y: bounds.Y - BallSize}
+}
+
+func pixelSpeed(b *Ball) float32 {
+	v := b.pixelVelocity()
+	return float32(math.Hypot(float64(v.X), float64(v.Y)))
+}
+
+func sign(v float32) float32 {
+	switch {
+	case v > 1e-6:
+		return 1
+	case v < -1e-6:
+		return -1
+	default:
+		return 0
+	}
+}
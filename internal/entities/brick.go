@@ -1,99 +1,207 @@
 package entities
 
 import (
+	"breakout/internal/bricks"
+	"breakout/internal/config"
 	"breakout/internal/types"
+	"encoding/json"
 	"image/color"
 
 	rl "github.com/gen2brain/raylib-go/raylib"
 )
 
-const (
-	BricksPerRow  = 14
-	BricksPerCol  = 8
-	BricksSpacing = 5
-	BricksYOffset = 200
-	BrickHeight   = 10
-)
+// BrickLayout is the grid the bricks are laid out on. Bricks stretch to fill
+// the playfield's width.
+type BrickLayout struct {
+	FieldWidth float32
+	PerRow     int32
+	PerCol     int32
+}
+
+// NewBrickLayout creates a brick grid with the given number of columns and
+// rows across the playfield
+func NewBrickLayout(cols, rows int32) BrickLayout {
+	return BrickLayout{
+		FieldWidth: config.PlayfieldWidth,
+		PerRow:     cols,
+		PerCol:     rows,
+	}
+}
+
+// Bounds returns the area of the brick at the given grid position
+func (l BrickLayout) Bounds(pos types.GridPos) types.Rectangle {
+	brickSize := (l.FieldWidth - float32((l.PerRow+1)*config.BrickSpacing)) / float32(l.PerRow)
+	x := float32(pos.Col)*(brickSize+config.BrickSpacing) + config.BrickSpacing
+	y := float32(pos.Row*(config.BrickHeight+config.BrickSpacing) + config.BrickSpacing + config.BrickYOffset)
+
+	return types.Rectangle{
+		X:      x,
+		Y:      y,
+		Width:  brickSize,
+		Height: float32(config.BrickHeight),
+	}
+}
+
+// CellAt returns the grid cell at point p. Each cell reaches halfway into the
+// gaps around its brick, so every point on the wall belongs to a cell.
+func (l BrickLayout) CellAt(p types.Vector2) (types.GridPos, bool) {
+	const margin = config.BrickSpacing / 2.0
+	for row := range l.PerCol {
+		for col := range l.PerRow {
+			pos := types.GridPos{Col: col, Row: row}
+			b := l.Bounds(pos)
+			if p.X >= b.X-margin && p.X < b.X+b.Width+margin && p.Y >= b.Y-margin && p.Y < b.Y+b.Height+margin {
+				return pos, true
+			}
+		}
+	}
+	return types.GridPos{}, false
+}
 
 // Brick represents a destructible brick
 type Brick struct {
-	color color.RGBA
-	pos   types.Vector2
+	kind     *bricks.Type
+	hitsLeft int32
+	// locked is set while the brick waits on types still in play, see
+	// bricks.Type.BreakableAfter
+	locked bool
+	// primed is set while the brick's fuse burns, before it explodes
+	primed bool
+	pos    types.GridPos
+	bounds types.Rectangle
 }
 
-// NewBrick creates a new brick at the specified grid position
-func NewBrick(x, y int32, color color.RGBA) *Brick {
+// NewBrick creates a new brick of the given type at the specified grid
+// position
+func NewBrick(layout BrickLayout, x, y int32, kind *bricks.Type) *Brick {
+	pos := types.GridPos{Col: x, Row: y}
 	return &Brick{
-		pos:   types.Vector2{X: x, Y: y},
-		color: color,
+		kind:     kind,
+		hitsLeft: kind.HitPoints,
+		pos:      pos,
+		bounds:   layout.Bounds(pos),
 	}
 }
 
 // GetBounds returns the collision bounds
 func (b *Brick) GetBounds() types.Rectangle {
-	brickSize := (WindowWidth - (BricksPerRow+1)*BricksSpacing) / BricksPerRow
-	x := float32(b.pos.X*int32(brickSize+BricksSpacing) + BricksSpacing)
-	y := float32(b.pos.Y*int32(BrickHeight+BricksSpacing) + BricksSpacing + BricksYOffset)
-	
-	return types.Rectangle{
-		X:      x,
-		Y:      y,
-		Width:  float32(brickSize),
-		Height: float32(BrickHeight),
-	}
+	return b.bounds
 }
 
-// Draw renders the brick
+// Draw renders the brick. A damaged brick is drawn darker, with a crack for
+// every hit it has taken, one that cannot be broken has a light border, and a
+// primed one glows.
 func (b *Brick) Draw() {
 	bounds := b.GetBounds()
-	rl.DrawRectangle(
-		int32(bounds.X),
-		int32(bounds.Y),
-		int32(bounds.Width),
-		int32(bounds.Height),
-		b.color,
-	)
+	// Snap both edges so neighbouring bricks keep an even gap between them
+	x := types.Snap(bounds.X)
+	y := types.Snap(bounds.Y)
+	width := types.Snap(bounds.X+bounds.Width) - x
+	height := types.Snap(bounds.Y+bounds.Height) - y
+
+	damage := b.Damage()
+	c := b.kind.Color
+	c = color.RGBA{R: darken(c.R, damage), G: darken(c.G, damage), B: darken(c.B, damage), A: c.A}
+	rl.DrawRectangle(x, y, width, height, c)
+	if b.primed {
+		rl.DrawRectangle(x, y, width, height, rl.Fade(rl.RayWhite, 0.6))
+	}
+	if !b.Breakable() {
+		rl.DrawRectangleLines(x, y, width, height, rl.Fade(rl.RayWhite, 0.6))
+	}
+
+	hits := b.kind.HitPoints - b.hitsLeft
+	for i := range min(hits, maxCracks) {
+		// Spread the cracks evenly across the brick, each a zigzag from top
+		// to bottom
+		cx := x + width*(i+1)/(min(hits, maxCracks)+1)
+		rl.DrawLine(cx, y, cx+2, y+height/2, rl.Black)
+		rl.DrawLine(cx+2, y+height/2, cx-1, y+height, rl.Black)
+	}
 }
 
-// GetValue returns the point value of the brick based on its row
-func (b *Brick) GetValue() int32 {
-	return 2*int32((7-b.pos.Y)/2) + 1
+// maxCracks is the most cracks drawn on a damaged brick
+const maxCracks = 4
+
+// darken shades a colour channel for a brick with the given damage, down to
+// half its brightness just before it breaks
+func darken(v uint8, damage float32) uint8 {
+	return uint8(float32(v) * (1 - damage/2))
 }
 
-// IsRed returns true if the brick is red
-func (b *Brick) IsRed() bool {
-	return b.color == rl.Red
+// Damage returns the share of its hit points the brick has lost, from 0 for
+// an untouched brick towards 1
+func (b *Brick) Damage() float32 {
+	return float32(b.kind.HitPoints-b.hitsLeft) / float32(b.kind.HitPoints)
 }
 
-// IsOrange returns true if the brick is orange
-func (b *Brick) IsOrange() bool {
-	return b.color == rl.Orange
+// GetValue returns the points the brick is worth, see bricks.Type.ScoreOn
+func (b *Brick) GetValue() int32 {
+	return b.kind.Points
 }
 
-// CreateLevelBricks creates all bricks for a level
-func CreateLevelBricks() []*Brick {
-	bricks := make([]*Brick, 0, BricksPerRow*BricksPerCol)
+// Type returns the kind of brick
+func (b *Brick) Type() *bricks.Type {
+	return b.kind
+}
 
-	for i := 0; i < BricksPerRow; i++ {
-		for j := 0; j < BricksPerCol; j++ {
-			color := getBrickColor(j)
-			brick := NewBrick(int32(i), int32(j), color)
-			bricks = append(bricks, brick)
-		}
+// Hit takes a hit point off the brick. It returns the points the hit scores
+// and reports whether it broke the brick.
+func (b *Brick) Hit() (points int32, broke bool) {
+	b.hitsLeft--
+	broke = b.hitsLeft <= 0
+	if broke || b.kind.ScoreOn != bricks.ScoreOnBreak {
+		points = b.kind.Points
 	}
+	return points, broke
+}
 
-	return bricks
+// Breakable reports whether a hit can break the brick now
+func (b *Brick) Breakable() bool {
+	return !b.kind.Indestructible && !b.locked
 }
 
-func getBrickColor(row int) color.RGBA {
-	switch {
-	case row >= 6:
-		return rl.Yellow
-	case row >= 4:
-		return rl.Green
-	case row >= 2:
-		return rl.Orange
-	default:
-		return rl.Red
-	}
-}
\ No newline at end of file
+// SetLocked keeps the brick from breaking while the types it waits on are
+// still in play
+func (b *Brick) SetLocked(locked bool) {
+	b.locked = locked
+}
+
+// Break breaks the brick whatever hits it has left, such as in an explosion.
+// It returns the points that scores, as for the hit that breaks it.
+func (b *Brick) Break() int32 {
+	b.hitsLeft = 0
+	return b.kind.Points
+}
+
+// Prime lights the brick's fuse, see bricks.Type.ExplosionRadius
+func (b *Brick) Prime() {
+	b.primed = true
+}
+
+// Primed reports whether the brick's fuse is burning
+func (b *Brick) Primed() bool {
+	return b.primed
+}
+
+// GridPos returns the brick's cell in the level's grid
+func (b *Brick) GridPos() types.GridPos {
+	return b.pos
+}
+
+// HitsLeft returns the hits the brick takes before it breaks
+func (b *Brick) HitsLeft() int32 {
+	return b.hitsLeft
+}
+
+// MarshalJSON encodes the brick's grid position, type, value and the hits it
+// has left
+func (b *Brick) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		Col      int32  `json:"col"`
+		Row      int32  `json:"row"`
+		Type     string `json:"type"`
+		Value    int32  `json:"value"`
+		HitsLeft int32  `json:"hits_left"`
+	}{b.pos.Col, b.pos.Row, b.kind.ID, b.GetValue(), b.hitsLeft})
+}
@@ -1,89 +1,91 @@
 package entities
 
 import (
+	"breakout/internal/bricks"
+	"breakout/internal/config"
+	"breakout/internal/types"
+	"math"
+	"slices"
 	"testing"
-
-	rl "github.com/gen2brain/raylib-go/raylib"
 )
 
-func TestBrickGetValue(t *testing.T) {
-	tests := []struct {
-		name     string
-		row      int32
-		expected int32
-	}{
-		{"Top row (0)", 0, 7},
-		{"Second row (1)", 1, 7},
-		{"Third row (2)", 2, 5},
-		{"Fourth row (3)", 3, 5},
-		{"Fifth row (4)", 4, 3},
-		{"Sixth row (5)", 5, 3},
-		{"Seventh row (6)", 6, 1},
-		{"Bottom row (7)", 7, 1},
-	}
+var testLayout = NewBrickLayout(14, 8)
 
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			brick := NewBrick(0, tt.row, rl.Red)
-			if got := brick.GetValue(); got != tt.expected {
-				t.Errorf("GetValue() = %v, want %v", got, tt.expected)
-			}
-		})
+func TestBrickGetValue(t *testing.T) {
+	for _, id := range bricks.Default().IDs() {
+		kind := bricks.Default().MustLookup(id)
+		brick := NewBrick(testLayout, 0, 0, kind)
+		if got := brick.GetValue(); got != kind.Points {
+			t.Errorf("%s GetValue() = %v, want %v", id, got, kind.Points)
+		}
 	}
 }
 
-func TestBrickColorChecks(t *testing.T) {
-	redBrick := NewBrick(0, 0, rl.Red)
-	orangeBrick := NewBrick(0, 0, rl.Orange)
-	greenBrick := NewBrick(0, 0, rl.Green)
+func TestBrickHitPoints(t *testing.T) {
+	tough := &bricks.Type{ID: "tough", Points: 2, ScoreOn: bricks.ScoreOnHit, HitPoints: 2}
+	brick := NewBrick(testLayout, 0, 0, tough)
 
-	if !redBrick.IsRed() {
-		t.Error("Red brick should return true for IsRed()")
+	if points, broke := brick.Hit(); broke || points != 2 {
+		t.Errorf("first Hit() = %d, %v, want 2 points and the brick whole", points, broke)
+	}
+	if got := brick.Damage(); got != 0.5 {
+		t.Errorf("Damage() after one of two hits = %v, want 0.5", got)
 	}
-	if redBrick.IsOrange() {
-		t.Error("Red brick should return false for IsOrange()")
+	if points, broke := brick.Hit(); !broke || points != 2 {
+		t.Errorf("second Hit() = %d, %v, want 2 points and the brick broken", points, broke)
 	}
+}
 
-	if orangeBrick.IsRed() {
-		t.Error("Orange brick should return false for IsRed()")
+func TestBrickScoresOnBreak(t *testing.T) {
+	armoured := &bricks.Type{ID: "armoured", Points: 20, ScoreOn: bricks.ScoreOnBreak, HitPoints: 3}
+	brick := NewBrick(testLayout, 0, 0, armoured)
+
+	var scored []int32
+	for range 3 {
+		points, _ := brick.Hit()
+		scored = append(scored, points)
 	}
-	if !orangeBrick.IsOrange() {
-		t.Error("Orange brick should return true for IsOrange()")
+	if !slices.Equal(scored, []int32{0, 0, 20}) {
+		t.Errorf("Hit() points = %v, want only the breaking hit to score", scored)
 	}
-
-	if greenBrick.IsRed() || greenBrick.IsOrange() {
-		t.Error("Green brick should return false for both IsRed() and IsOrange()")
+	if brick.HitsLeft() != 0 {
+		t.Errorf("HitsLeft() = %d after three of three hits, want 0", brick.HitsLeft())
 	}
 }
 
-func TestCreateLevelBricks(t *testing.T) {
-	bricks := CreateLevelBricks()
-
-	expectedCount := BricksPerRow * BricksPerCol
-	if len(bricks) != expectedCount {
-		t.Errorf("Expected %d bricks, got %d", expectedCount, len(bricks))
+func TestBrickLayoutCellAt(t *testing.T) {
+	for _, pos := range []types.GridPos{{Col: 0, Row: 0}, {Col: 13, Row: 7}, {Col: 6, Row: 3}} {
+		b := NewBrick(testLayout, pos.Col, pos.Row, bricks.Default().MustLookup("red")).GetBounds()
+		corners := []types.Vector2{
+			{X: b.X, Y: b.Y},
+			{X: b.X + b.Width - 0.01, Y: b.Y + b.Height - 0.01},
+			// In the gap, closer to this brick than to the next
+			{X: b.X + b.Width + 2, Y: b.Y + b.Height + 2},
+		}
+		for _, p := range corners {
+			if got, ok := testLayout.CellAt(p); !ok || got != pos {
+				t.Errorf("CellAt(%v) = %v, %v, want %v", p, got, ok, pos)
+			}
+		}
 	}
 
-	// Check that we have the right colors in the right rows
-	colorCounts := make(map[string]int)
-	for _, brick := range bricks {
-		switch brick.color {
-		case rl.Red:
-			colorCounts["red"]++
-		case rl.Orange:
-			colorCounts["orange"]++
-		case rl.Green:
-			colorCounts["green"]++
-		case rl.Yellow:
-			colorCounts["yellow"]++
-		}
+	// Above the wall is no cell
+	if got, ok := testLayout.CellAt(types.Vector2{X: 100, Y: 50}); ok {
+		t.Errorf("CellAt() above the wall = %v, want none", got)
 	}
+}
+
+func TestBrickLayoutFillsFieldWidth(t *testing.T) {
+	for _, perRow := range []int32{1, 4, 14, 20} {
+		layout := BrickLayout{FieldWidth: 500, PerRow: perRow, PerCol: 1}
 
-	// Each color should appear in exactly 2 rows * BricksPerRow bricks
-	expectedPerColor := 2 * BricksPerRow
-	for color, count := range colorCounts {
-		if count != expectedPerColor {
-			t.Errorf("Expected %d %s bricks, got %d", expectedPerColor, color, count)
+		first := layout.Bounds(types.GridPos{Col: 0})
+		last := layout.Bounds(types.GridPos{Col: perRow - 1})
+		if first.X != config.BrickSpacing {
+			t.Errorf("PerRow %d: first brick starts at %v, want %v", perRow, first.X, config.BrickSpacing)
+		}
+		if right := last.X + last.Width; math.Abs(float64(right-(500-config.BrickSpacing))) > 1e-3 {
+			t.Errorf("PerRow %d: last brick ends at %v, want %v", perRow, right, 500-config.BrickSpacing)
 		}
 	}
-}
\ No newline at end of file
+}
@@ -1,7 +1,10 @@
 package entities
 
 import (
+	"breakout/internal/config"
+	"breakout/internal/input"
 	"breakout/internal/types"
+	"encoding/json"
 
 	rl "github.com/gen2brain/raylib-go/raylib"
 )
@@ -9,25 +12,34 @@ import (
 const (
 	PlayerPaddleHeight = 20
 	PlayerPaddleWidth  = 100
-	PlayerPaddleYPos   = WindowHeight - 100
-	PlayerBaseSpeed    = 0.3
 )
 
 // PlayerPaddle represents the player's paddle
 type PlayerPaddle struct {
 	width      float32
 	x          float32
+	prevX      float32
+	y          float32
+	velocity   float32
+	baseSpeed  float32
 	speed      float32
 	speedScale int32
+	fieldWidth float32
+	input      input.Source
 }
 
-// NewPlayerPaddle creates a new player paddle
-func NewPlayerPaddle(x float32) *PlayerPaddle {
+// NewPlayerPaddle creates a new player paddle driven by the given input source
+func NewPlayerPaddle(cfg config.Config, x float32, in input.Source) *PlayerPaddle {
 	return &PlayerPaddle{
 		width:      PlayerPaddleWidth,
 		x:          x,
-		speed:      PlayerBaseSpeed * 2,
-		speedScale: 2,
+		prevX:      x,
+		y:          config.PlayfieldHeight - config.PaddleMargin,
+		baseSpeed:  cfg.Game.PaddleBaseSpeed,
+		speed:      cfg.Game.PaddleBaseSpeed * float32(cfg.Controls.PaddleSpeed),
+		speedScale: cfg.Controls.PaddleSpeed,
+		fieldWidth: config.PlayfieldWidth,
+		input:      in,
 	}
 }
 
@@ -36,6 +48,12 @@ func (p *PlayerPaddle) X() float32 {
 	return p.x
 }
 
+// Velocity returns the paddle's horizontal velocity during its last update, in
+// pixels per second
+func (p *PlayerPaddle) Velocity() float32 {
+	return p.velocity
+}
+
 // Width returns the paddle's width
 func (p *PlayerPaddle) Width() float32 {
 	return p.width
@@ -43,10 +61,10 @@ func (p *PlayerPaddle) Width() float32 {
 
 // GetBounds returns the collision bounds
 func (p *PlayerPaddle) GetBounds() types.Rectangle {
-	px := p.x*float32(WindowWidth) - p.width/2
+	px := p.x*p.fieldWidth - p.width/2
 	return types.Rectangle{
 		X:      px,
-		Y:      float32(PlayerPaddleYPos),
+		Y:      p.y,
 		Width:  p.width,
 		Height: float32(PlayerPaddleHeight),
 	}
@@ -54,8 +72,20 @@ func (p *PlayerPaddle) GetBounds() types.Rectangle {
 
 // Draw renders the paddle
 func (p *PlayerPaddle) Draw() {
-	px := int32(p.x*float32(WindowWidth) - p.width/2)
-	rl.DrawRectangle(px, PlayerPaddleYPos, int32(p.width), PlayerPaddleHeight, rl.RayWhite)
+	p.DrawInterpolated(1)
+}
+
+// DrawInterpolated renders the paddle blended between its previous and current
+// tick positions, alpha being the fraction of a tick elapsed since the last one
+func (p *PlayerPaddle) DrawInterpolated(alpha float32) {
+	x := types.Lerp(p.prevX, p.x, alpha)
+	px := types.Snap(x*p.fieldWidth - p.width/2)
+	rl.DrawRectangle(px, types.Snap(p.y), types.Snap(p.width), PlayerPaddleHeight, rl.RayWhite)
+}
+
+// SavePrevious records the current position as the start of the next tick
+func (p *PlayerPaddle) SavePrevious() {
+	p.prevX = p.x
 }
 
 // Update handles paddle movement and speed changes
@@ -64,38 +94,73 @@ func (p *PlayerPaddle) Update(deltaTime float32) {
 	p.handleSpeedChange()
 }
 
-// HalveWidth reduces the paddle width by half
-func (p *PlayerPaddle) HalveWidth() {
-	p.width /= 2
+// SetBaseSpeed changes the speed the paddle's speed setting scales, keeping
+// the setting itself
+func (p *PlayerPaddle) SetBaseSpeed(speed float32) {
+	p.baseSpeed = speed
+	p.speed = speed * float32(p.speedScale)
+}
+
+// SetSpeedScale changes the paddle's speed setting, as the player does with
+// W and S
+func (p *PlayerPaddle) SetSpeedScale(scale int32) {
+	p.speedScale = max(config.MinPaddleSpeed, min(config.MaxPaddleSpeed, scale))
+	p.speed = p.baseSpeed * float32(p.speedScale)
+}
+
+// ScaleWidth multiplies the paddle's width by factor, keeping it at least as
+// wide as the ball and no wider than the playfield
+func (p *PlayerPaddle) ScaleWidth(factor float32) {
+	p.width = min(max(p.width*factor, BallSize), p.fieldWidth)
+}
+
+// MarshalJSON encodes the paddle's position, width and speed setting
+func (p *PlayerPaddle) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		X          float32 `json:"x"`
+		Width      float32 `json:"width"`
+		SpeedScale int32   `json:"speed_scale"`
+	}{p.x, p.width, p.speedScale})
 }
 
 func (p *PlayerPaddle) handleMovement(deltaTime float32) {
-	keyToDelta := map[int32]float32{
-		rl.KeyA: -p.speed,
-		rl.KeyD: p.speed,
+	start := p.x
+
+	// Slices rather than maps so keys are applied in the same order every tick
+	keyToDelta := []struct {
+		key   int32
+		delta float32
+	}{
+		{rl.KeyA, -p.speed},
+		{rl.KeyD, p.speed},
 	}
 
-	for key, delta := range keyToDelta {
-		if rl.IsKeyDown(key) {
-			p.x += delta * deltaTime
+	for _, kd := range keyToDelta {
+		if p.input.IsKeyDown(kd.key) {
+			p.x += kd.delta * deltaTime
 		}
 	}
 
 	// Clamp position to screen bounds
 	p.x = max(0, min(1, p.x))
+
+	if deltaTime > 0 {
+		p.velocity = (p.x - start) * p.fieldWidth / deltaTime
+	}
 }
 
 func (p *PlayerPaddle) handleSpeedChange() {
-	keyToScale := map[int32]int32{
-		rl.KeyW: 1,
-		rl.KeyS: -1,
+	keyToScale := []struct {
+		key   int32
+		scale int32
+	}{
+		{rl.KeyW, 1},
+		{rl.KeyS, -1},
 	}
 
-	for key, scale := range keyToScale {
-		if rl.IsKeyPressed(key) {
-			p.speedScale += scale
-			p.speedScale = max(1, min(5, p.speedScale))
-			p.speed = PlayerBaseSpeed * float32(p.speedScale)
+	for _, ks := range keyToScale {
+		if p.input.IsKeyPressed(ks.key) {
+			p.SetSpeedScale(p.speedScale + ks.scale)
 		}
 	}
-}
\ No newline at end of file
+}
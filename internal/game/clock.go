@@ -0,0 +1,39 @@
+package game
+
+const (
+	// TickRate is the number of fixed simulation steps per second
+	TickRate = 144
+	// TickDuration is the simulated time covered by a single tick, in seconds
+	TickDuration = float32(1) / TickRate
+	// MaxFrameTime caps how much time a single frame may feed into the
+	// simulation, so a long hitch does not trigger a burst of catch-up ticks
+	MaxFrameTime = 0.25
+)
+
+// Clock converts variable frame times into a whole number of fixed ticks
+type Clock struct {
+	accumulator float64
+}
+
+// NewClock creates a clock with an empty accumulator
+func NewClock() *Clock {
+	return &Clock{}
+}
+
+// Advance adds a frame's worth of time and returns how many ticks are due
+func (c *Clock) Advance(frameTime float32) int {
+	c.accumulator += float64(max(0, min(MaxFrameTime, frameTime)))
+
+	ticks := 0
+	for c.accumulator >= float64(TickDuration) {
+		c.accumulator -= float64(TickDuration)
+		ticks++
+	}
+	return ticks
+}
+
+// Alpha returns how far the clock is between the last tick and the next, in
+// the range [0, 1). Rendering uses it to interpolate entity positions.
+func (c *Clock) Alpha() float32 {
+	return float32(c.accumulator / float64(TickDuration))
+}
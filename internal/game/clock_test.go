@@ -0,0 +1,52 @@
+package game
+
+import "testing"
+
+func TestClockTickCountIndependentOfFrameRate(t *testing.T) {
+	const seconds = 10
+
+	tests := []struct {
+		name string
+		fps  int
+	}{
+		{"30 FPS", 30},
+		{"60 FPS", 60},
+		{"144 FPS", 144},
+		{"240 FPS", 240},
+		{"1000 FPS", 1000},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clock := NewClock()
+			ticks := 0
+			for i := 0; i < seconds*tt.fps; i++ {
+				ticks += clock.Advance(1 / float32(tt.fps))
+			}
+
+			// Allow one tick of drift from float rounding at the boundary
+			want := seconds * TickRate
+			if ticks < want-1 || ticks > want {
+				t.Errorf("Ran %d ticks in %d seconds, want %d", ticks, seconds, want)
+			}
+		})
+	}
+}
+
+func TestClockCapsLongFrames(t *testing.T) {
+	clock := NewClock()
+	ticks := clock.Advance(5)
+
+	if limit := int(MaxFrameTime * TickRate); ticks > limit {
+		t.Errorf("A 5 second hitch ran %d ticks, want at most %d", ticks, limit)
+	}
+}
+
+func TestClockAlpha(t *testing.T) {
+	clock := NewClock()
+	clock.Advance(TickDuration * 2.5)
+
+	if alpha := clock.Alpha(); alpha < 0.49 || alpha > 0.51 {
+		t.Errorf("Alpha() = %v, want 0.5", alpha)
+	}
+}
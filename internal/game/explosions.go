@@ -0,0 +1,89 @@
+package game
+
+import (
+	"breakout/internal/entities"
+	"breakout/internal/types"
+	"slices"
+)
+
+// fuseTicks is how long an explosive brick caught in an explosion burns
+// before it goes off itself, so a chain reaction spreads slowly enough to
+// follow
+const fuseTicks = 8
+
+// fuse is an explosive brick set to go off
+type fuse struct {
+	brick *entities.Brick
+	// ticksLeft counts down the ticks played until the brick explodes.
+	// Ticks spent paused or with the game over do not count.
+	ticksLeft int
+}
+
+// explode sets off an explosive brick that has just been broken and removed.
+// Every breakable brick within its radius is destroyed, scoring and counting
+// as a hit that breaks it, except for explosive bricks, which are primed to
+// explode in turn.
+func (g *Game) explode(brick *entities.Brick) {
+	// The brick may have broken while primed, before its fuse burnt down
+	g.fuses = slices.DeleteFunc(g.fuses, func(f fuse) bool {
+		return f.brick == brick
+	})
+
+	center := brick.GridPos()
+	radius := brick.Type().ExplosionRadius
+	var removed []int
+	for i, b := range g.state.Bricks {
+		if !b.Breakable() || b.Primed() || distance(b.GridPos(), center) > radius {
+			continue
+		}
+		if b.Type().ExplosionRadius > 0 {
+			b.Prime()
+			g.fuses = append(g.fuses, fuse{brick: b, ticksLeft: fuseTicks})
+			continue
+		}
+		g.countHit(b, b.Break())
+		removed = append(removed, i)
+	}
+	g.removeBricks(removed)
+	g.audio.PlayBrickHit()
+}
+
+// burnFuses burns every fuse down by a tick and explodes the primed bricks
+// whose fuses run out. It runs once per tick played, before the ball moves, so
+// a fuse lit on one tick first burns on the next.
+func (g *Game) burnFuses() {
+	var due []*entities.Brick
+	burning := g.fuses[:0]
+	for _, f := range g.fuses {
+		if f.ticksLeft--; f.ticksLeft > 0 {
+			burning = append(burning, f)
+		} else {
+			due = append(due, f.brick)
+		}
+	}
+	g.fuses = burning
+
+	// The explosions light fuses of their own, which start burning next tick
+	for _, brick := range due {
+		i := slices.Index(g.state.Bricks, brick)
+		if i < 0 {
+			continue
+		}
+		g.countHit(brick, brick.Break())
+		g.removeBricks([]int{i})
+		g.explode(brick)
+	}
+}
+
+// distance returns how many cells apart two grid cells are, counting
+// diagonal steps as one, so a radius covers a square of cells
+func distance(a, b types.GridPos) int32 {
+	return max(abs(a.Col-b.Col), abs(a.Row-b.Row))
+}
+
+func abs(v int32) int32 {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
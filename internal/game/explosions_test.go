@@ -0,0 +1,181 @@
+package game
+
+import (
+	"breakout/internal/config"
+	"breakout/internal/entities"
+	"breakout/internal/levels"
+	"breakout/internal/types"
+	"strings"
+	"testing"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
+
+func TestExplosionsChain(t *testing.T) {
+	g, _ := newChainGame(t, "{}\nYYYYYY\nYYYYYY\nYEEE.E\n")
+
+	// Each explosive brick destroys the bricks around it and primes the
+	// explosives among them, shown in lower case, to go off a fuse later
+	steps := []struct {
+		ticks int
+		wall  string
+		score int32
+		hits  int32
+	}{
+		{0, "YYYYYY\n...YYY\n..eE.E\n", 5 + 4, 5},
+		{fuseTicks - 1, "YYYYYY\n...YYY\n..eE.E\n", 5 + 4, 5},
+		{fuseTicks, "YYYYYY\n....YY\n...e.E\n", 5 + 4 + 5 + 1, 7},
+		{2*fuseTicks - 1, "YYYYYY\n....YY\n...e.E\n", 5 + 4 + 5 + 1, 7},
+		{2 * fuseTicks, "YYYYYY\n.....Y\n.....E\n", 5 + 4 + 5 + 1 + 5 + 1, 9},
+		{3 * fuseTicks, "YYYYYY\n.....Y\n.....E\n", 5 + 4 + 5 + 1 + 5 + 1, 9},
+	}
+
+	hitFromBelow(g, brickAt(g, 1, 2))
+	// The bricks the explosion destroyed count towards the speed-up after
+	// four hits, which only the one brick the ball hit would not reach
+	if speed := g.state.Ball.Speed(); speed <= 0.4 {
+		t.Errorf("ball speed = %v after the explosion, want it sped up from 0.4", speed)
+	}
+	played := 0
+	for _, step := range steps {
+		for ; played < step.ticks; played++ {
+			g.Tick()
+		}
+		checkChain(t, g, played, step.wall, step.score, step.hits)
+	}
+}
+
+func TestFusesWaitWhilePaused(t *testing.T) {
+	g, keys := newChainGame(t, "{}\nYYYYYY\nYYYYYY\nYEEE.E\n")
+	hitFromBelow(g, brickAt(g, 1, 2))
+	for range fuseTicks / 2 {
+		g.Tick()
+	}
+
+	// Losing the ball pauses the game part way through the chain, for
+	// longer than any fuse
+	g.loseBall()
+	for range 3 * fuseTicks {
+		g.Tick()
+	}
+	checkChain(t, g, fuseTicks/2, "YYYYYY\n...YYY\n..eE.E\n", 5+4, 5)
+
+	// The tick that serves the ball again does not burn the fuses either,
+	// which go on from where they were
+	keys.press(rl.KeySpace)
+	g.Tick()
+	for range fuseTicks/2 - 1 {
+		g.Tick()
+	}
+	checkChain(t, g, fuseTicks-1, "YYYYYY\n...YYY\n..eE.E\n", 5+4, 5)
+	g.Tick()
+	checkChain(t, g, fuseTicks, "YYYYYY\n....YY\n...e.E\n", 5+4+5+1, 7)
+	for range fuseTicks {
+		g.Tick()
+	}
+	checkChain(t, g, 2*fuseTicks, "YYYYYY\n.....Y\n.....E\n", 5+4+5+1+5+1, 9)
+}
+
+func TestPrimedBrickExplodesWhenHit(t *testing.T) {
+	g := newPlayingGame(t)
+	level, err := levels.Parse([]byte("{}\nYYY\nEEY\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	g.setBricks(level.Bricks())
+
+	first, second := brickAt(g, 0, 1), brickAt(g, 1, 1)
+	hitFromBelow(g, first)
+	if !second.Primed() {
+		t.Fatal("explosive next to the exploded one was not primed")
+	}
+
+	// Hitting the primed brick sets it off at once, not again later
+	hitFromBelow(g, second)
+	if got := wall(g, 3, 2); got != "...\n...\n" {
+		t.Errorf("wall after hitting the primed brick =\n%s", got)
+	}
+	if len(g.fuses) != 0 {
+		t.Errorf("%d fuses left burning, want none", len(g.fuses))
+	}
+}
+
+// newChainGame starts a game on the level, read from a source of scripted key
+// presses that presses nothing unless told to
+func newChainGame(t *testing.T, level string) (*Game, *scriptedKeys) {
+	t.Helper()
+	l, err := levels.Parse([]byte(level))
+	if err != nil {
+		t.Fatal(err)
+	}
+	keys := &scriptedKeys{}
+	g := newHeadless(t, config.Default(), keys)
+	g.PlayLevels([]*levels.Level{l})
+	g.Initialize()
+	g.state.Paused = false
+	return g, keys
+}
+
+// checkChain checks the wall, score and hit count after ticks played ticks
+// of a chain reaction
+func checkChain(t *testing.T, g *Game, ticks int, want string, score, hits int32) {
+	t.Helper()
+	if got := wall(g, 6, 3); got != want {
+		t.Errorf("after %d ticks the wall is\n%s\nwant\n%s", ticks, got, want)
+	}
+	if g.state.Score != score || g.state.BrickHitCount != hits {
+		t.Errorf("after %d ticks Score, BrickHitCount = %d, %d, want %d, %d",
+			ticks, g.state.Score, g.state.BrickHitCount, score, hits)
+	}
+}
+
+// scriptedKeys is an input source that presses keys on the next tick only
+type scriptedKeys struct {
+	pressed, next map[int32]bool
+}
+
+// press presses key on the next tick
+func (s *scriptedKeys) press(key int32) {
+	if s.next == nil {
+		s.next = make(map[int32]bool)
+	}
+	s.next[key] = true
+}
+
+func (s *scriptedKeys) Poll()                       { s.pressed, s.next = s.next, nil }
+func (s *scriptedKeys) IsKeyDown(key int32) bool    { return s.pressed[key] }
+func (s *scriptedKeys) IsKeyPressed(key int32) bool { return s.pressed[key] }
+
+// brickAt returns the brick in play at a grid cell
+func brickAt(g *Game, col, row int32) *entities.Brick {
+	for _, b := range g.state.Bricks {
+		if b.GridPos() == (types.GridPos{Col: col, Row: row}) {
+			return b
+		}
+	}
+	return nil
+}
+
+// wall draws the bricks left in play as a level grid, with primed bricks in
+// lower case
+func wall(g *Game, cols, rows int) string {
+	grid := make([][]byte, rows)
+	for row := range grid {
+		grid[row] = []byte(strings.Repeat(".", cols))
+	}
+	for _, b := range g.state.Bricks {
+		symbol := b.Type().Symbol
+		if b.Primed() {
+			symbol = strings.ToLower(string(symbol))[0]
+		}
+		pos := b.GridPos()
+		grid[pos.Row][pos.Col] = symbol
+	}
+
+	var s strings.Builder
+	for _, row := range grid {
+		s.Write(row)
+		s.WriteByte('\n')
+	}
+	return s.String()
+}
@@ -2,215 +2,653 @@ package game
 
 import (
 	"breakout/internal/audio"
+	"breakout/internal/config"
 	"breakout/internal/entities"
+	"breakout/internal/input"
+	"breakout/internal/levels"
 	"breakout/internal/physics"
 	"breakout/internal/renderer"
+	"breakout/internal/rules"
+	"breakout/internal/scores"
 	"breakout/internal/types"
+	"slices"
 
 	rl "github.com/gen2brain/raylib-go/raylib"
 )
 
 const (
-	WindowWidth  = 768
-	WindowHeight = 1024
-	MaxLevels    = 2
+	// maxContactsPerTick bounds how many contacts the ball resolves in a
+	// single tick, guarding against getting wedged between two surfaces
+	maxContactsPerTick = 8
+	// contactTimeEpsilon is how close in time two contacts must be to count
+	// as simultaneous, as a fraction of the swept motion
+	contactTimeEpsilon = 1e-4
 )
 
 // Game represents the main game state and logic
 type Game struct {
+	cfg      config.Config
 	state    *State
 	renderer *renderer.Renderer
 	audio    *audio.Manager
 	physics  *physics.Engine
+	input    input.Source
+	clock    *Clock
+	ticks    uint64
+	spin     entities.Spin
+	levels   []*levels.Level
+
+	// fuses are the explosive bricks set to go off, see explosions.go
+	fuses []fuse
+
+	// Hot reload state, see reload.go
+	pendingConfig *config.Config
+	notice        string
+	noticeTicks   int
+	configErrors  []string
+
+	// highScores are shown once the game is over
+	highScores []scores.Entry
+
+	// screens tracks whether the settings screen is open over the game.
+	// settings is nil unless EnableSettings was called.
+	screens  *GameStateManager
+	settings *settingsScreen
 }
 
-// State holds the current game state
+// State holds the current game state. Difficulty tags the run with its preset
+// as named by config.DifficultyTag, and Serves counts the balls left,
+// including the one in play.
 type State struct {
-	Level         int32
-	Score         int32
-	BrickHitCount int32
-	GameLost      bool
-	GameWon       bool
-	Paused        bool
+	Difficulty    string `json:"difficulty"`
+	Level         int32  `json:"level"`
+	Score         int32  `json:"score"`
+	BrickHitCount int32  `json:"brick_hit_count"`
+	Serves        int32  `json:"serves"`
+	GameLost      bool   `json:"game_lost"`
+	GameWon       bool   `json:"game_won"`
+	Paused        bool   `json:"paused"`
+
+	Player *entities.PlayerPaddle `json:"player"`
+	Ball   *entities.Ball         `json:"ball"`
+	Bricks []*entities.Brick      `json:"bricks"`
+
+	Rules *rules.Engine `json:"rules"`
+}
 
-	Player *entities.PlayerPaddle
-	Ball   *entities.Ball
-	Bricks []*entities.Brick
+// New creates a new game instance with the given configuration, reading from
+// the given input source
+func New(cfg config.Config, in input.Source) (*Game, error) {
+	levels, err := levels.Load(cfg.Game.LevelsDir)
+	if err != nil {
+		return nil, err
+	}
+	audioManager, err := audio.New(cfg.Audio)
+	if err != nil {
+		return nil, err
+	}
 
-	ChangeConditions *entities.ChangeStateConditions
+	return &Game{
+		cfg:      cfg,
+		state:    &State{},
+		renderer: renderer.New(),
+		audio:    audioManager,
+		physics:  physics.New(),
+		input:    in,
+		clock:    NewClock(),
+		spin:     spinFromConfig(cfg.Game),
+		levels:   levels,
+		screens:  NewGameStateManager(),
+	}, nil
 }
 
-// New creates a new game instance
-func New() (*Game, error) {
-	audioManager, err := audio.New()
+// NewHeadless creates a game that needs neither a window nor an audio device.
+// Only Initialize, Update and Tick may be called on it.
+func NewHeadless(cfg config.Config, in input.Source) (*Game, error) {
+	levels, err := levels.Load(cfg.Game.LevelsDir)
 	if err != nil {
 		return nil, err
 	}
 
 	return &Game{
+		cfg:      cfg,
 		state:    &State{},
 		renderer: renderer.New(),
-		audio:    audioManager,
+		audio:    audio.NewSilent(),
 		physics:  physics.New(),
+		input:    in,
+		clock:    NewClock(),
+		spin:     spinFromConfig(cfg.Game),
+		levels:   levels,
+		screens:  NewGameStateManager(),
 	}, nil
 }
 
+func spinFromConfig(cfg config.GameConfig) entities.Spin {
+	return entities.Spin{
+		English:  cfg.PaddleEnglish,
+		Transfer: cfg.SpinTransfer,
+		Decay:    cfg.SpinDecay,
+	}
+}
+
 // Initialize sets up the initial game state
 func (g *Game) Initialize() {
+	g.state.Difficulty = config.DifficultyTag(g.cfg.Game)
 	g.state.Level = 1
 	g.state.Score = 0
 	g.state.BrickHitCount = 0
+	g.state.Serves = g.cfg.Game.Serves
 	g.state.GameLost = false
 	g.state.GameWon = false
 	g.state.Paused = true
+	g.screens.TransitionTo(StatePlaying)
+
+	g.state.Player = entities.NewPlayerPaddle(g.cfg, 0.5, g.input)
+	g.state.Ball = entities.NewBall(g.cfg)
+	g.state.Ball.IncreaseSpeed(g.level().BallSpeed)
+	g.setBricks(g.level().Bricks())
+	g.state.Rules = rules.NewEngine(g.cfg.Game.Rules, TickDuration)
+	g.applyEffects(g.state.Rules.LevelStart(g.state.Level))
+}
+
+// CurrentLevel returns the level being played, or the last one once the game
+// is won, and its index among the levels the game is played through
+func (g *Game) CurrentLevel() (*levels.Level, int) {
+	return g.level(), int(min(g.state.Level, g.levelCount()) - 1)
+}
+
+// ReplaceLevel swaps the current level for l, such as once it has been edited.
+// The bricks in play stay as they are until the level next starts.
+func (g *Game) ReplaceLevel(l *levels.Level) {
+	_, i := g.CurrentLevel()
+	// The levels may be shared, as the built-in ones are
+	g.levels = slices.Clone(g.levels)
+	g.levels[i] = l
+}
 
-	g.state.Player = entities.NewPlayerPaddle(0.5)
-	g.state.Ball = entities.NewBall()
-	g.state.Bricks = entities.CreateLevelBricks()
-	g.state.ChangeConditions = entities.NewChangeStateConditions()
+// PlayLevels replaces the levels the game is played through, such as to try
+// out a level being edited. Call Initialize afterwards to start on the first.
+func (g *Game) PlayLevels(levels []*levels.Level) {
+	g.levels = levels
 }
 
-// Update handles game logic updates
-func (g *Game) Update(deltaTime float32) {
-	if g.isLevelComplete() && g.state.Level <= MaxLevels {
+// Update advances the simulation by a frame's worth of time, running as many
+// fixed ticks as have become due
+func (g *Game) Update(frameTime float32) {
+	if g.updateSettings() {
+		return
+	}
+	for range g.clock.Advance(frameTime) {
+		if g.InputDone() {
+			return
+		}
+		g.Tick()
+	}
+}
+
+// InputDone reports whether the game's input source has run out, after which
+// Update no longer advances the game
+func (g *Game) InputDone() bool {
+	finite, ok := g.input.(input.Finite)
+	return ok && finite.Done()
+}
+
+// Tick advances the simulation by exactly one fixed step. Given the same input
+// on every tick, the game always plays out the same way.
+func (g *Game) Tick() {
+	g.ticks++
+	g.input.Poll()
+	g.applyPendingConfig()
+	g.noticeTicks = max(0, g.noticeTicks-1)
+
+	g.state.Player.SavePrevious()
+	g.state.Ball.SavePrevious()
+
+	if g.isLevelComplete() && g.state.Level <= g.levelCount() {
 		g.advanceLevel()
 	}
 
-	if g.state.Level > MaxLevels {
+	if g.state.Level > g.levelCount() {
 		g.state.GameWon = true
 		return
 	}
 
 	if g.isGameOver() {
-		if rl.IsKeyPressed(rl.KeyR) {
+		if g.input.IsKeyPressed(rl.KeyR) {
 			g.Initialize()
 		}
 		return
 	}
 
 	if g.state.Paused {
-		if rl.IsKeyPressed(rl.KeySpace) {
+		if g.input.IsKeyPressed(rl.KeySpace) {
 			g.state.Paused = false
 		}
 		return
 	}
 
-	g.state.Player.Update(deltaTime)
-	g.updateBall(deltaTime)
+	g.burnFuses()
+	g.state.Player.Update(TickDuration)
+	g.updateBall(TickDuration)
 }
 
 // Draw renders the current game state
 func (g *Game) Draw() {
+	level := g.level()
+	g.renderer.DrawBackground(level.Background)
+	g.drawConfigStatus()
+
+	if g.InSettings() {
+		g.settings.draw(g.NextConfig(), g.renderer)
+		return
+	}
+
 	if g.state.GameWon {
-		g.renderer.DrawGameWon(g.state.Score)
+		g.renderer.DrawGameWon(g.state.Score, keyLabel(g.cfg.Controls.Restart))
+		g.renderer.DrawHighScores(g.state.Difficulty, g.highScores)
 		return
 	}
 
 	if g.state.GameLost {
-		g.renderer.DrawGameLost(g.state.Score)
+		g.renderer.DrawGameLost(g.state.Score, keyLabel(g.cfg.Controls.Restart))
+		g.renderer.DrawHighScores(g.state.Difficulty, g.highScores)
 		return
 	}
 
 	if g.state.Paused {
-		g.renderer.DrawPaused()
+		g.renderer.DrawPaused(keyLabel(g.cfg.Controls.Serve))
 	}
 
+	alpha := g.clock.Alpha()
+
 	g.renderer.DrawScore(g.state.Score)
-	g.state.Player.Draw()
-	g.state.Ball.Draw()
+	g.renderer.DrawStatus(g.state.Serves, g.state.Difficulty)
+	g.renderer.DrawLevel(g.state.Level, level.Name, level.Par)
+	g.state.Player.DrawInterpolated(alpha)
+	g.state.Ball.DrawInterpolated(alpha)
 
 	for _, brick := range g.state.Bricks {
 		brick.Draw()
 	}
 }
 
+// EnableSettings lets the player open the settings screen with Escape during
+// play. save stores the settings changed on it and applies them.
+func (g *Game) EnableSettings(save SaveSettings) {
+	g.settings = &settingsScreen{save: save}
+}
+
+// InSettings reports whether the settings screen is open
+func (g *Game) InSettings() bool {
+	return g.screens.CurrentState() == StateSettings
+}
+
+// EditRequested reports whether the player chose to edit the level on the
+// settings screen since it was last called. The settings screen stays open, to
+// return to once the level has been edited.
+func (g *Game) EditRequested() bool {
+	if g.settings == nil || !g.settings.edit {
+		return false
+	}
+	g.settings.edit = false
+	return true
+}
+
+// QuitRequested reports whether the player chose to quit on the settings
+// screen
+func (g *Game) QuitRequested() bool {
+	return g.settings != nil && g.settings.quit
+}
+
+// updateSettings runs the settings screen for a frame, opening it when the
+// player presses settingsKey. It reports whether the screen is open, in which
+// case the game stands still.
+func (g *Game) updateSettings() bool {
+	if g.settings == nil {
+		return false
+	}
+
+	if !g.InSettings() {
+		if !rl.IsKeyPressed(settingsKey) {
+			return false
+		}
+		g.screens.TransitionTo(StateSettings)
+		return true
+	}
+
+	if !g.settings.update(g.NextConfig(), g.renderer) {
+		g.settings.message = ""
+		g.screens.TransitionTo(g.screens.PreviousState())
+	}
+	return true
+}
+
+// Ticks returns the number of ticks simulated since the game was created
+func (g *Game) Ticks() uint64 {
+	return g.ticks
+}
+
+// IsOver reports whether the game has been won or lost
+func (g *Game) IsOver() bool {
+	return g.isGameOver()
+}
+
+// SetHighScores sets the high scores shown when the game is over
+func (g *Game) SetHighScores(entries []scores.Entry) {
+	g.highScores = entries
+}
+
+// State returns the current game state
+func (g *Game) State() *State {
+	return g.state
+}
+
 // Cleanup releases game resources
 func (g *Game) Cleanup() {
 	g.audio.Cleanup()
 }
 
+// isLevelComplete reports whether every brick that can be broken is gone.
+// Indestructible bricks are left standing.
 func (g *Game) isLevelComplete() bool {
-	return len(g.state.Bricks) == 0
+	return !slices.ContainsFunc(g.state.Bricks, func(b *entities.Brick) bool {
+		return !b.Type().Indestructible
+	})
 }
 
 func (g *Game) isGameOver() bool {
 	return g.state.GameLost || g.state.GameWon
 }
 
+// levelCount returns the number of levels in a game
+func (g *Game) levelCount() int32 {
+	return int32(len(g.levels))
+}
+
+// level returns the level being played, or the last one once the game is won
+func (g *Game) level() *levels.Level {
+	return g.levels[min(g.state.Level, g.levelCount())-1]
+}
+
+// advanceLevel moves on to the next level, if there is one. The ball keeps
+// its speed, rescaled to the new level's ball speed.
 func (g *Game) advanceLevel() {
+	previous := g.level()
 	g.state.Level++
-	g.state.Bricks = entities.CreateLevelBricks()
+	if g.state.Level > g.levelCount() {
+		g.setBricks(nil)
+		return
+	}
+
+	level := g.level()
+	g.state.Ball.IncreaseSpeed(level.BallSpeed / previous.BallSpeed)
+	g.setBricks(level.Bricks())
+	g.applyEffects(g.state.Rules.LevelStart(g.state.Level))
+}
+
+// setBricks replaces the bricks in play and rebuilds the broadphase index.
+// Bricks are indexed by their position in State.Bricks.
+func (g *Game) setBricks(bricks []*entities.Brick) {
+	g.state.Bricks = bricks
+	g.fuses = nil
+	g.physics.ClearStatic()
+	for i, brick := range bricks {
+		g.physics.AddStatic(i, brick.GetBounds())
+	}
+	g.lockBricks()
+}
+
+// lockBricks locks every brick that waits on types still in play, and
+// unlocks the rest. Call it whenever bricks are removed.
+func (g *Game) lockBricks() {
+	inPlay := make(map[string]bool)
+	for _, b := range g.state.Bricks {
+		inPlay[b.Type().ID] = true
+	}
+	for _, b := range g.state.Bricks {
+		b.SetLocked(slices.ContainsFunc(b.Type().BreakableAfter, func(id string) bool {
+			return inPlay[id]
+		}))
+	}
+}
+
+// removeBricks removes the bricks at the given indices. Each removal moves the
+// last brick into the freed slot, so it costs the same on any board size.
+func (g *Game) removeBricks(indices []int) {
+	slices.Sort(indices)
+	for _, i := range slices.Backward(indices) {
+		last := len(g.state.Bricks) - 1
+		g.physics.RemoveStatic(i)
+		if i != last {
+			moved := g.state.Bricks[last]
+			g.state.Bricks[i] = moved
+			g.physics.RemoveStatic(last)
+			g.physics.AddStatic(i, moved.GetBounds())
+		}
+		g.state.Bricks[last] = nil
+		g.state.Bricks = g.state.Bricks[:last]
+	}
+	if len(indices) > 0 {
+		g.lockBricks()
+	}
+}
+
+// ballContact is a contact between the ball and the paddle or a brick
+type ballContact struct {
+	normal types.Vector2
+	// brick is the index of the brick hit, or -1 for the paddle
+	brick int
 }
 
 func (g *Game) updateBall(deltaTime float32) {
-	oldPos := g.state.Ball.Position()
+	// Let spin bend the ball's path a little
+	g.state.Ball.Curve(deltaTime, g.spin.Decay)
 
-	g.state.Ball.Update(deltaTime)
+	// Move the ball along its path, bouncing off anything in the way
+	g.handleCollisions(deltaTime)
 
 	// Check wall collisions
-	if g.state.Ball.Position().Y <= 0 && !g.state.ChangeConditions.UpperWallHit {
-		g.state.ChangeConditions.UpperWallHit = true
-		g.state.Player.HalveWidth()
+	g.state.Ball.BounceOffWalls()
+	if g.state.Ball.HitCeiling() {
+		g.applyEffects(g.state.Rules.CeilingHit())
 	}
 
-	// Check game over condition
-	if g.state.Ball.Position().Y+entities.BallSize >= WindowHeight {
-		g.state.GameLost = true
+	// Check for a lost ball
+	if g.state.Ball.Position().Y+entities.BallSize >= config.PlayfieldHeight {
+		g.loseBall()
 		return
 	}
 
-	// Check collisions with game objects
-	g.handleCollisions(oldPos)
+	g.applyEffects(g.state.Rules.Tick(g.state.BrickHitCount))
+}
+
+// handleCollisions sweeps the ball along its motion for this tick. The earliest
+// contacts are resolved first and the ball spends the rest of the tick moving
+// along its new velocity, so fast balls cannot tunnel through thin objects.
+func (g *Game) handleCollisions(deltaTime float32) {
+	g.separateFromPaddle()
+	g.separateBall()
+
+	remaining := deltaTime
+
+	for i := 0; i < maxContactsPerTick && remaining > 0; i++ {
+		delta := g.state.Ball.Displacement(remaining)
+
+		hits, toi, ok := g.findEarliestContacts(delta)
+		if !ok {
+			g.state.Ball.Move(delta)
+			return
+		}
+
+		g.state.Ball.Move(types.Vector2{
+			X: delta.X * toi,
+			Y: delta.Y * toi,
+		})
+		g.resolveContacts(hits)
 
-	// Check speed increase conditions
-	g.checkSpeedIncreaseConditions()
+		remaining *= 1 - toi
+	}
 }
 
-func (g *Game) handleCollisions(oldBallPos types.Vector2) {
-	// Check paddle collision
-	if g.physics.CheckCollision(g.state.Ball, g.state.Player) {
-		g.state.Ball.ReflectOffPaddle(g.state.Player)
+// separateFromPaddle pushes the ball out of the paddle, which can move into
+// the ball from the side or trap it after a bad bounce. The ball only bounces
+// if it is still moving into the paddle.
+func (g *Game) separateFromPaddle() {
+	p, ok := g.physics.CircleRect(g.state.Ball.Circle(), g.state.Player.GetBounds())
+	if !ok {
+		return
+	}
+
+	g.state.Ball.Move(types.Vector2{X: p.Normal.X * p.Depth, Y: p.Normal.Y * p.Depth})
+	if g.state.Ball.BounceOffPaddle(g.state.Player, p.Normal, g.spin) {
 		g.audio.PlayPaddleHit()
+	}
+}
+
+// separateBall pushes the ball out of any bricks it already overlaps, such as
+// bricks of a new level spawned on top of it, and treats them as hit
+func (g *Game) separateBall() {
+	ballBounds := g.state.Ball.GetBounds()
+
+	candidates := g.physics.QueryStatic(ballBounds)
+	bounds := make([]types.Rectangle, len(candidates))
+	for i, index := range candidates {
+		bounds[i] = g.state.Bricks[index].GetBounds()
+	}
+
+	sep, ok := g.physics.Separate(g.state.Ball.Circle(), bounds)
+	if !ok {
 		return
 	}
 
-	// Check brick collisions
-	for i, brick := range g.state.Bricks {
-		if g.physics.CheckCollision(g.state.Ball, brick) {
-			g.state.Ball.ReflectOffBrick(brick)
-			g.audio.PlayBrickHit()
+	g.state.Ball.Move(sep.Correction)
 
-			g.state.Score += brick.GetValue()
-			g.state.BrickHitCount++
+	hits := make([]ballContact, len(sep.Hits))
+	for i, hit := range sep.Hits {
+		hits[i] = ballContact{normal: sep.Normal, brick: candidates[hit]}
+	}
+	g.resolveContacts(hits)
+}
 
-			// Handle special brick effects
-			g.handleBrickEffects(brick)
+// findEarliestContacts returns every contact that happens at the earliest time
+// of impact along delta, and that time
+func (g *Game) findEarliestContacts(delta types.Vector2) ([]ballContact, float32, bool) {
+	ball := g.state.Ball.Circle()
+	ballBounds := g.state.Ball.GetBounds()
+
+	var hits []ballContact
+	var toi float32
+
+	consider := func(c physics.Contact, brick int) {
+		switch {
+		case len(hits) == 0 || c.Time < toi-contactTimeEpsilon:
+			hits = append(hits[:0], ballContact{normal: c.Normal, brick: brick})
+			toi = c.Time
+		case c.Time <= toi+contactTimeEpsilon:
+			hits = append(hits, ballContact{normal: c.Normal, brick: brick})
+			toi = min(toi, c.Time)
+		}
+	}
 
-			// Remove brick
-			g.state.Bricks = append(g.state.Bricks[:i], g.state.Bricks[i+1:]...)
-			return
+	if c, ok := g.physics.Sweep(ball, delta, g.state.Player.GetBounds()); ok {
+		consider(c, -1)
+	}
+
+	for _, i := range g.physics.QueryStatic(physics.SweptArea(ballBounds, delta)) {
+		if c, ok := g.physics.Sweep(ball, delta, g.state.Bricks[i].GetBounds()); ok {
+			consider(c, i)
 		}
 	}
+
+	return hits, toi, len(hits) > 0
 }
 
-func (g *Game) handleBrickEffects(brick *entities.Brick) {
-	if brick.IsRed() && !g.state.ChangeConditions.RedContact {
-		g.state.ChangeConditions.RedContact = true
-		g.state.Ball.IncreaseSpeed(entities.BallSpeedIncrement)
-	} else if brick.IsOrange() && !g.state.ChangeConditions.OrangeContact {
-		g.state.ChangeConditions.OrangeContact = true
-		g.state.Ball.IncreaseSpeed(entities.BallSpeedIncrement)
+// resolveContacts handles a set of simultaneous contacts. Every breakable
+// brick touched loses a hit point, breaking when it has none left, and scores
+// as its type says. Explosive bricks explode as they break. The ball bounces
+// once off the combined surface.
+func (g *Game) resolveContacts(hits []ballContact) {
+	normals := make([]types.Vector2, 0, len(hits))
+	removed := make([]int, 0, len(hits))
+	var exploded []*entities.Brick
+	var paddleHit *ballContact
+
+	for _, hit := range hits {
+		if hit.brick < 0 {
+			paddleHit = &hit
+			continue
+		}
+
+		normals = append(normals, hit.normal)
+
+		// Bricks that cannot be broken yet are walls: they are not hit
+		brick := g.state.Bricks[hit.brick]
+		if !brick.Breakable() {
+			continue
+		}
+		points, broke := brick.Hit()
+		if broke {
+			removed = append(removed, hit.brick)
+			if brick.Type().ExplosionRadius > 0 {
+				exploded = append(exploded, brick)
+			}
+		}
+		g.countHit(brick, points)
 	}
+
+	if paddleHit != nil {
+		if g.state.Ball.BounceOffPaddle(g.state.Player, paddleHit.normal, g.spin) {
+			g.audio.PlayPaddleHit()
+		}
+	} else {
+		g.state.Ball.Reflect(physics.CombineNormals(normals...))
+	}
+
+	if len(normals) == 0 {
+		return
+	}
+	g.audio.PlayBrickHit()
+
+	g.removeBricks(removed)
+	for _, brick := range exploded {
+		g.explode(brick)
+	}
+}
+
+// countHit scores a hit on a brick and runs the rules it fires
+func (g *Game) countHit(brick *entities.Brick, points int32) {
+	g.state.Score += points
+	g.state.BrickHitCount++
+	g.applyEffects(g.state.Rules.BrickHit(brick.Type().ID))
 }
 
-func (g *Game) checkSpeedIncreaseConditions() {
-	if g.state.BrickHitCount >= 4 && !g.state.ChangeConditions.FourHits {
-		g.state.ChangeConditions.FourHits = true
-		g.state.Ball.IncreaseSpeed(entities.BallSpeedIncrement)
+// loseBall ends the game once the last serve is lost. Otherwise it serves a
+// new ball at the speed the lost one had reached and pauses until the player
+// is ready.
+func (g *Game) loseBall() {
+	g.state.Serves--
+	if g.state.Serves <= 0 {
+		g.state.GameLost = true
+		return
 	}
 
-	if g.state.BrickHitCount >= 12 && !g.state.ChangeConditions.TwelveHits {
-		g.state.ChangeConditions.TwelveHits = true
-		g.state.Ball.IncreaseSpeed(entities.BallSpeedIncrement)
+	lost := g.state.Ball
+	g.state.Ball = entities.NewBall(g.cfg)
+	g.state.Ball.IncreaseSpeed(lost.Speed() / g.state.Ball.Speed())
+	g.state.Paused = true
+}
+
+// applyEffects carries out the effects of the rules that fired
+func (g *Game) applyEffects(effects []config.Effect) {
+	for _, e := range effects {
+		switch e.Action {
+		case config.ActionBallSpeed:
+			g.state.Ball.IncreaseSpeed(e.Factor)
+		case config.ActionPaddleWidth:
+			g.state.Player.ScaleWidth(e.Factor)
+		case config.ActionExtraBall:
+			g.state.Serves += e.Count
+		}
 	}
-}
\ No newline at end of file
+}
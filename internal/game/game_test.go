@@ -0,0 +1,651 @@
+package game
+
+import (
+	"breakout/internal/bricks"
+	"breakout/internal/config"
+	"breakout/internal/entities"
+	"breakout/internal/input"
+	"breakout/internal/levels"
+	"breakout/internal/types"
+	"encoding/json"
+	"math"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
+
+// newHeadless creates a headless game, failing the test if it cannot
+func newHeadless(t *testing.T, cfg config.Config, in input.Source) *Game {
+	t.Helper()
+	g, err := NewHeadless(cfg, in)
+	if err != nil {
+		t.Fatalf("NewHeadless() error = %v", err)
+	}
+	return g
+}
+
+func simulate(t *testing.T, seed int64, ticks int) []byte {
+	t.Helper()
+
+	g := newHeadless(t, config.Default(), input.NewRandom(seed))
+	g.Initialize()
+	for i := 0; i < ticks; i++ {
+		g.Tick()
+	}
+
+	data, err := json.Marshal(g.State())
+	if err != nil {
+		t.Fatalf("Failed to encode state: %v", err)
+	}
+	return data
+}
+
+func TestTickIsDeterministic(t *testing.T) {
+	for _, seed := range []int64{1, 2, 3, 42} {
+		first := simulate(t, seed, 5000)
+		second := simulate(t, seed, 5000)
+		if string(first) != string(second) {
+			t.Errorf("Seed %d produced different states on two runs", seed)
+		}
+	}
+}
+
+func TestUpdateRunsWholeTicks(t *testing.T) {
+	g := newHeadless(t, config.Default(), input.NewRandom(1))
+	g.Initialize()
+
+	g.Update(TickDuration * 3.5)
+	if got := g.Ticks(); got != 3 {
+		t.Errorf("Ticks() = %d after 3.5 ticks of time, want 3", got)
+	}
+
+	g.Update(TickDuration * 0.6)
+	if got := g.Ticks(); got != 4 {
+		t.Errorf("Ticks() = %d after 4.1 ticks of time, want 4", got)
+	}
+}
+
+func newPlayingGame(t *testing.T) *Game {
+	t.Helper()
+
+	g := newHeadless(t, config.Default(), input.NewRandom(1))
+	g.Initialize()
+	g.state.Paused = false
+	return g
+}
+
+func TestFastBallDoesNotTunnelThroughBrick(t *testing.T) {
+	g := newPlayingGame(t)
+
+	brick := newBrick(g, 5, 7)
+	bounds := brick.GetBounds()
+	g.setBricks([]*entities.Brick{brick})
+
+	// Fast enough to cover several brick heights in a single tick
+	start := types.Vector2{X: bounds.X + 10, Y: bounds.Y + bounds.Height + 20}
+	g.state.Ball = entities.NewBallAt(start, rl.Vector2{Y: -20})
+	if step := g.state.Ball.Displacement(TickDuration).Y; -step < 3*(bounds.Height+entities.BallSize) {
+		t.Fatalf("Test ball only moves %v pixels per tick, too slow to tunnel", -step)
+	}
+
+	g.updateBall(TickDuration)
+
+	if len(g.state.Bricks) != 0 {
+		t.Fatal("Fast ball passed through the brick without hitting it")
+	}
+	if vy := g.state.Ball.Velocity().Y; vy <= 0 {
+		t.Errorf("Ball velocity Y = %v after hitting the brick from below, want positive", vy)
+	}
+	if y := g.state.Ball.Position().Y; y < bounds.Y+bounds.Height {
+		t.Errorf("Ball ended at Y = %v, inside or above the brick it bounced off", y)
+	}
+}
+
+func TestBallKeepsLeftoverMotionAfterBounce(t *testing.T) {
+	g := newPlayingGame(t)
+
+	brick := newBrick(g, 5, 7)
+	bounds := brick.GetBounds()
+	g.setBricks([]*entities.Brick{brick})
+
+	// Place the ball so it touches the brick halfway through the tick
+	g.state.Ball = entities.NewBallAt(types.Vector2{}, rl.Vector2{Y: -1})
+	step := -g.state.Ball.Displacement(TickDuration).Y
+	start := types.Vector2{X: bounds.X + 10, Y: bounds.Y + bounds.Height + step/2}
+	g.state.Ball = entities.NewBallAt(start, rl.Vector2{Y: -1})
+
+	g.updateBall(TickDuration)
+
+	// Half the step up to the brick, half back down again
+	if diff := g.state.Ball.Position().Y - start.Y; diff > 0.01 || diff < -0.01 {
+		t.Errorf("Ball ended at Y = %v, want %v", g.state.Ball.Position().Y, start.Y)
+	}
+}
+
+func TestSeamHitRemovesBothBricksAndReflectsOnce(t *testing.T) {
+	g := newPlayingGame(t)
+
+	left := newBrick(g, 5, 7)
+	right := newBrick(g, 6, 7)
+	g.setBricks([]*entities.Brick{left, right})
+
+	// Centre the ball under the gap between the two bricks, moving straight up
+	lb, rb := left.GetBounds(), right.GetBounds()
+	gapCenter := (lb.X + lb.Width + rb.X) / 2
+	start := types.Vector2{X: gapCenter - entities.BallSize/2, Y: lb.Y + lb.Height + 1}
+	g.state.Ball = entities.NewBallAt(start, rl.Vector2{Y: -0.4})
+
+	g.updateBall(TickDuration)
+
+	if len(g.state.Bricks) != 0 {
+		t.Errorf("%d bricks left after a seam hit, want 0", len(g.state.Bricks))
+	}
+	if vy := g.state.Ball.Velocity().Y; vy <= 0 {
+		t.Errorf("Ball velocity Y = %v after the seam hit, want positive", vy)
+	}
+	if g.state.BrickHitCount != 2 {
+		t.Errorf("BrickHitCount = %d, want 2", g.state.BrickHitCount)
+	}
+}
+
+func TestMultiHitBrickBreaksOnLastHit(t *testing.T) {
+	tests := []struct {
+		name   string
+		kind   *bricks.Type
+		scores []int32
+	}{
+		{"Score on hit", &bricks.Type{ID: "tough", Points: 4, ScoreOn: bricks.ScoreOnHit, HitPoints: 3}, []int32{4, 8, 12}},
+		{"Score on break", bricks.Default().MustLookup("silver"), []int32{0, 0, 20}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := newPlayingGame(t)
+			brick := entities.NewBrick(g.level().Layout(), 5, 7, tt.kind)
+			g.setBricks([]*entities.Brick{brick})
+
+			for hit, want := range tt.scores {
+				hitFromBelow(g, brick)
+
+				if g.state.Score != want {
+					t.Errorf("Score after hit %d = %d, want %d", hit+1, g.state.Score, want)
+				}
+				if last := hit == len(tt.scores)-1; (len(g.state.Bricks) == 0) != last {
+					t.Errorf("%d bricks left after hit %d of %d", len(g.state.Bricks), hit+1, len(tt.scores))
+				}
+			}
+			if g.state.BrickHitCount != int32(len(tt.scores)) {
+				t.Errorf("BrickHitCount = %d, want every hit counted", g.state.BrickHitCount)
+			}
+		})
+	}
+}
+
+func TestIndestructibleBrickIsAWall(t *testing.T) {
+	g := newPlayingGame(t)
+	layout := g.level().Layout()
+	steel := entities.NewBrick(layout, 5, 7, bricks.Default().MustLookup("steel"))
+	yellow := entities.NewBrick(layout, 0, 0, bricks.Default().MustLookup("yellow"))
+	g.setBricks([]*entities.Brick{steel, yellow})
+
+	for range 3 {
+		hitFromBelow(g, steel)
+		if vy := g.state.Ball.Velocity().Y; vy <= 0 {
+			t.Fatalf("Ball velocity Y = %v after hitting steel, want it bounced back down", vy)
+		}
+	}
+	if len(g.state.Bricks) != 2 || g.state.Score != 0 || g.state.BrickHitCount != 0 {
+		t.Errorf("after hitting steel: %d bricks, score %d, %d hits, want both bricks and no score or hits",
+			len(g.state.Bricks), g.state.Score, g.state.BrickHitCount)
+	}
+	if g.isLevelComplete() {
+		t.Error("isLevelComplete() = true with a yellow brick left")
+	}
+
+	// Only the steel is left, which does not need clearing
+	g.removeBricks([]int{1})
+	if !g.isLevelComplete() {
+		t.Error("isLevelComplete() = false with only steel left")
+	}
+}
+
+func TestSealedBrickBreaksOnceRedIsCleared(t *testing.T) {
+	g := newPlayingGame(t)
+	layout := g.level().Layout()
+	sealed := entities.NewBrick(layout, 5, 7, bricks.Default().MustLookup("sealed"))
+	red := entities.NewBrick(layout, 8, 7, bricks.Default().MustLookup("red"))
+	g.setBricks([]*entities.Brick{sealed, red})
+
+	hitFromBelow(g, sealed)
+	if len(g.state.Bricks) != 2 || g.state.Score != 0 {
+		t.Fatalf("%d bricks and score %d after hitting the sealed brick with red in play, want it untouched", len(g.state.Bricks), g.state.Score)
+	}
+
+	hitFromBelow(g, red)
+	if !sealed.Breakable() {
+		t.Fatal("sealed brick still locked once the red brick is gone")
+	}
+	hitFromBelow(g, sealed)
+	if len(g.state.Bricks) != 0 || g.state.Score != 7+10 {
+		t.Errorf("%d bricks and score %d after hitting the unlocked brick, want none and 17", len(g.state.Bricks), g.state.Score)
+	}
+}
+
+func TestBallIsPushedOutOfOverlappingBrick(t *testing.T) {
+	g := newPlayingGame(t)
+
+	brick := newBrick(g, 5, 7)
+	bounds := brick.GetBounds()
+	g.setBricks([]*entities.Brick{brick})
+
+	// A brick spawned on top of the ball, which is poking 3 pixels into it
+	start := types.Vector2{X: bounds.X + 10, Y: bounds.Y + bounds.Height - 3}
+	g.state.Ball = entities.NewBallAt(start, rl.Vector2{Y: -0.4})
+
+	g.updateBall(TickDuration)
+
+	if len(g.state.Bricks) != 0 {
+		t.Error("Overlapped brick was not hit")
+	}
+	if y := g.state.Ball.Position().Y; y < bounds.Y+bounds.Height {
+		t.Errorf("Ball at Y = %v is still inside the brick", y)
+	}
+	if vy := g.state.Ball.Velocity().Y; vy <= 0 {
+		t.Errorf("Ball velocity Y = %v, want positive", vy)
+	}
+}
+
+func TestCornerHitDeflectsAtAngle(t *testing.T) {
+	g := newPlayingGame(t)
+
+	brick := newBrick(g, 5, 7)
+	bounds := brick.GetBounds()
+	g.setBricks([]*entities.Brick{brick})
+
+	// Rising straight up with the centre 3 pixels right of the brick's
+	// bottom-right corner, so the ball catches the corner
+	center := types.Vector2{X: bounds.X + bounds.Width + 3, Y: bounds.Y + bounds.Height + 10}
+	start := types.Vector2{X: center.X - entities.BallRadius, Y: center.Y - entities.BallRadius}
+	g.state.Ball = entities.NewBallAt(start, rl.Vector2{Y: -0.4})
+
+	g.updateBall(TickDuration * 10)
+
+	if len(g.state.Bricks) != 0 {
+		t.Fatal("Ball missed the brick corner")
+	}
+
+	// The corner normal is (0.6, 0.8), which turns straight-up motion into
+	// a shallow bounce down and to the right
+	step := g.state.Ball.Displacement(1)
+	if step.X <= 0 || step.Y <= 0 {
+		t.Fatalf("Ball moves by %v after the corner hit, want down and to the right", step)
+	}
+	if ratio := step.X / step.Y; ratio < 3.3 || ratio > 3.5 {
+		t.Errorf("Ball deflected with dx/dy = %v, want about 3.43", ratio)
+	}
+}
+
+// heldKeys is an input source that holds the same keys on every tick
+type heldKeys map[int32]bool
+
+func (h heldKeys) Poll()                       {}
+func (h heldKeys) IsKeyDown(key int32) bool    { return h[key] }
+func (h heldKeys) IsKeyPressed(key int32) bool { return false }
+
+// placeBall puts the ball's centre at the given point
+func placeBall(g *Game, center types.Vector2, velocity rl.Vector2) {
+	pos := types.Vector2{X: center.X - entities.BallRadius, Y: center.Y - entities.BallRadius}
+	g.state.Ball = entities.NewBallAt(pos, velocity)
+	g.setBricks(nil)
+}
+
+// hitFromBelow sends the ball straight up into the brick and plays the tick
+// it hits
+func hitFromBelow(g *Game, brick *entities.Brick) {
+	bounds := brick.GetBounds()
+	start := types.Vector2{X: bounds.X + 10, Y: bounds.Y + bounds.Height + 1}
+	g.state.Ball = entities.NewBallAt(start, rl.Vector2{Y: -0.4})
+	g.updateBall(TickDuration)
+}
+
+// newBrick creates a brick at the given grid position in the game's layout
+func newBrick(g *Game, col, row int32) *entities.Brick {
+	return entities.NewBrick(g.level().Layout(), col, row, bricks.Default().MustLookup("yellow"))
+}
+
+func overlapsPaddle(g *Game) bool {
+	p, ok := g.physics.CircleRect(g.state.Ball.Circle(), g.state.Player.GetBounds())
+	return ok && p.Depth > 1e-3
+}
+
+func TestPaddleEdgeCases(t *testing.T) {
+	paddle := entities.NewPlayerPaddle(config.Default(), 0.5, heldKeys{}).GetBounds()
+
+	tests := []struct {
+		name     string
+		center   types.Vector2
+		velocity rl.Vector2
+		check    func(t *testing.T, v types.Vector2)
+	}{
+		{
+			name:     "Top face sends the ball up",
+			center:   types.Vector2{X: paddle.X + paddle.Width/2, Y: paddle.Y - 6},
+			velocity: rl.Vector2{X: 0.1, Y: 0.4},
+			check: func(t *testing.T, v types.Vector2) {
+				if v.Y >= 0 {
+					t.Errorf("Ball moves by %v after a top hit, want upwards", v)
+				}
+			},
+		},
+		{
+			name:     "Side face bounces the ball sideways",
+			center:   types.Vector2{X: paddle.X - 6, Y: paddle.Y + paddle.Height/2},
+			velocity: rl.Vector2{X: 0.4, Y: 0.1},
+			check: func(t *testing.T, v types.Vector2) {
+				if v.X >= 0 || v.Y <= 0 {
+					t.Errorf("Ball moves by %v after a side hit, want left and still down", v)
+				}
+			},
+		},
+		{
+			name:     "Underside bounces the ball down",
+			center:   types.Vector2{X: paddle.X + paddle.Width/2, Y: paddle.Y + paddle.Height + 6},
+			velocity: rl.Vector2{X: 0.1, Y: -0.4},
+			check: func(t *testing.T, v types.Vector2) {
+				if v.Y <= 0 {
+					t.Errorf("Ball moves by %v after an underside hit, want downwards", v)
+				}
+			},
+		},
+		{
+			name:     "Ball moving away is not reflected again",
+			center:   types.Vector2{X: paddle.X + paddle.Width/2, Y: paddle.Y + 2},
+			velocity: rl.Vector2{X: 0.1, Y: -0.4},
+			check: func(t *testing.T, v types.Vector2) {
+				if v.Y >= 0 {
+					t.Errorf("Ball moves by %v, want it to keep moving up", v)
+				}
+			},
+		},
+		{
+			name:     "Ball trapped inside is pushed out",
+			center:   types.Vector2{X: paddle.X + 20, Y: paddle.Y + paddle.Height/2},
+			velocity: rl.Vector2{X: 0.1, Y: 0.4},
+			check: func(t *testing.T, v types.Vector2) {
+				if v.Y >= 0 {
+					t.Errorf("Ball moves by %v after escaping the paddle, want upwards", v)
+				}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := newHeadless(t, config.Default(), heldKeys{})
+			g.Initialize()
+			g.state.Paused = false
+			placeBall(g, tt.center, tt.velocity)
+
+			g.Tick()
+
+			if overlapsPaddle(g) {
+				t.Errorf("Ball at %v still overlaps the paddle", g.state.Ball.Position())
+			}
+			tt.check(t, g.state.Ball.Displacement(1))
+		})
+	}
+}
+
+func TestPaddleMovingIntoBallPushesItOut(t *testing.T) {
+	g := newHeadless(t, config.Default(), heldKeys{rl.KeyD: true})
+	g.Initialize()
+	g.state.Paused = false
+
+	// The ball sits just right of the paddle, drifting slowly down, while the
+	// paddle drives into it
+	paddle := g.state.Player.GetBounds()
+	placeBall(g, types.Vector2{X: paddle.X + paddle.Width + 6, Y: paddle.Y + 5}, rl.Vector2{Y: 0.01})
+
+	for i := 0; i < 20; i++ {
+		g.Tick()
+		if overlapsPaddle(g) {
+			t.Fatalf("Tick %d: ball at %v overlaps the paddle", i, g.state.Ball.Position())
+		}
+	}
+}
+
+func TestReconfigureAppliesAtNextTick(t *testing.T) {
+	g := newPlayingGame(t)
+	placeBall(g, types.Vector2{X: 300, Y: 500}, rl.Vector2{X: 0.3, Y: -0.4})
+	// A brick out of the ball's way keeps the level going, as the next one
+	// would change the ball's speed
+	g.setBricks([]*entities.Brick{newBrick(g, 0, 0)})
+	speed := g.state.Ball.Displacement(1)
+
+	cfg := g.cfg
+	cfg.Game.BallBaseSpeed *= 2
+	cfg.Game.Serves = 7
+	cfg.Window.Height = 2000
+
+	restart := g.Reconfigure(cfg)
+	if len(restart) != 1 || restart[0] != "window.height" {
+		t.Errorf("Reconfigure() = %v, want [window.height]", restart)
+	}
+	if g.cfg.Game.Serves == 7 {
+		t.Fatal("Reconfigure() changed the config before the next tick")
+	}
+	if got := g.NextConfig().Game.Serves; got != 7 {
+		t.Errorf("NextConfig().Game.Serves = %d before the tick, want 7", got)
+	}
+
+	g.Tick()
+
+	if g.cfg.Game.Serves != 7 {
+		t.Errorf("Serves = %d after the tick, want 7", g.cfg.Game.Serves)
+	}
+	if g.cfg.Window.Height == 2000 {
+		t.Error("Window.Height changed without a restart")
+	}
+	// The ball keeps its direction and doubles its speed
+	got := g.state.Ball.Displacement(1)
+	if math.Abs(float64(got.X-2*speed.X)) > 1e-3 || math.Abs(float64(got.Y-2*speed.Y)) > 1e-3 {
+		t.Errorf("Ball velocity = %v, want %v", got, types.Vector2{X: 2 * speed.X, Y: 2 * speed.Y})
+	}
+}
+
+func TestConfigErrorKeepsConfig(t *testing.T) {
+	g := newPlayingGame(t)
+	before := g.cfg
+
+	_, err := config.Parse([]byte(`{"game": {"serves": 0}}`))
+	g.ShowConfigError(err)
+	g.Tick()
+
+	if !reflect.DeepEqual(g.cfg, before) {
+		t.Errorf("config changed after an invalid reload: %+v", g.cfg)
+	}
+	if len(g.configErrors) != 2 {
+		t.Errorf("configErrors = %q, want a heading and one problem", g.configErrors)
+	}
+
+	g.Reconfigure(before)
+	if g.configErrors != nil {
+		t.Errorf("configErrors = %q after a good reload, want none", g.configErrors)
+	}
+}
+
+func TestLostBallIsServedAgain(t *testing.T) {
+	g := newPlayingGame(t)
+	g.state.Serves = 2
+	bottom := float32(config.PlayfieldHeight)
+	placeBall(g, types.Vector2{X: 50, Y: bottom - 8}, rl.Vector2{X: 0.6, Y: 0.8})
+
+	g.updateBall(TickDuration)
+
+	if g.state.GameLost || g.state.Serves != 1 || !g.state.Paused {
+		t.Fatalf("after losing a ball: GameLost = %v, Serves = %d, Paused = %v, want false, 1, true",
+			g.state.GameLost, g.state.Serves, g.state.Paused)
+	}
+	// The new ball keeps the speed the lost one had reached
+	if got := g.state.Ball.Speed(); math.Abs(float64(got-1)) > 1e-5 {
+		t.Errorf("served ball speed = %v, want 1", got)
+	}
+
+	placeBall(g, types.Vector2{X: 50, Y: bottom - 8}, rl.Vector2{Y: 1})
+	g.updateBall(TickDuration)
+	if !g.state.GameLost {
+		t.Error("GameLost = false after losing the last ball")
+	}
+}
+
+func TestPresetRules(t *testing.T) {
+	easy, _ := config.LookupPreset("easy")
+	cfg := config.Default()
+	cfg.Game = easy.Apply(cfg.Game)
+
+	g := newHeadless(t, cfg, input.NewRandom(1))
+	g.Initialize()
+	g.state.Paused = false
+	if g.state.Difficulty != "easy" || g.state.Serves != easy.Game.Serves {
+		t.Fatalf("Difficulty, Serves = %q, %d, want easy, %d", g.state.Difficulty, g.state.Serves, easy.Game.Serves)
+	}
+
+	// Easy keeps the paddle whole at the ceiling
+	width := g.state.Player.Width()
+	placeBall(g, types.Vector2{X: 300, Y: entities.BallRadius + 0.5}, rl.Vector2{X: 0.1, Y: -0.2})
+	g.updateBall(TickDuration)
+	if g.state.Player.Width() != width {
+		t.Errorf("paddle width after a ceiling hit = %v, want %v", g.state.Player.Width(), width)
+	}
+
+	// and has no speed-up after four hits
+	g.state.BrickHitCount = 4
+	speed := g.state.Ball.Speed()
+	g.applyEffects(g.state.Rules.Tick(g.state.BrickHitCount))
+	if g.state.Ball.Speed() != speed {
+		t.Error("easy sped the ball up after four hits")
+	}
+
+	// Tuning a value mid-run drops the run to custom
+	cfg.Game.BallBaseSpeed = 0.9
+	g.Reconfigure(cfg)
+	g.Tick()
+	if g.state.Difficulty != config.CustomDifficulty {
+		t.Errorf("Difficulty = %q after tuning, want %q", g.state.Difficulty, config.CustomDifficulty)
+	}
+}
+
+func TestRulesFromConfig(t *testing.T) {
+	cfg := config.Default()
+	cfg.Game.Rules = []config.Rule{
+		{
+			Name:    "bonus_ball",
+			Trigger: config.Trigger{Event: config.EventLevelStart},
+			Effect:  config.Effect{Action: config.ActionExtraBall, Count: 2},
+			Policy:  config.PolicyRepeat,
+		},
+		{
+			Name:    "wide_paddle",
+			Trigger: config.Trigger{Event: config.EventBrickHit, Brick: "yellow"},
+			Effect:  config.Effect{Action: config.ActionPaddleWidth, Factor: 1.5},
+			Policy:  config.PolicyOnce,
+		},
+	}
+
+	g := newHeadless(t, cfg, input.NewRandom(1))
+	g.Initialize()
+	if want := cfg.Game.Serves + 2; g.state.Serves != want {
+		t.Errorf("Serves at the first level = %d, want %d", g.state.Serves, want)
+	}
+
+	width := g.state.Player.Width()
+	g.applyEffects(g.state.Rules.BrickHit("yellow"))
+	g.applyEffects(g.state.Rules.BrickHit("yellow"))
+	if got := g.state.Player.Width(); got != width*1.5 {
+		t.Errorf("paddle width after two yellow hits = %v, want %v", got, width*1.5)
+	}
+}
+
+func TestLevelsFromDirectory(t *testing.T) {
+	dir := t.TempDir()
+	for name, content := range map[string]string{
+		"01.level": "{\"name\": \"One\", \"ball_speed\": 2}\nRR\n",
+		"02.level": "{\"name\": \"Two\", \"ball_speed\": 3}\n.Y.\n",
+	} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	cfg := config.Default()
+	cfg.Game.LevelsDir = dir
+
+	g := newHeadless(t, cfg, input.NewRandom(1))
+	g.Initialize()
+	if len(g.state.Bricks) != 2 || g.level().Name != "One" {
+		t.Fatalf("first level has %d bricks on %q, want 2 on One", len(g.state.Bricks), g.level().Name)
+	}
+	// The ball starts at the level's speed
+	base := entities.NewBall(cfg).Speed()
+	if got := g.state.Ball.Speed(); math.Abs(float64(got-2*base)) > 1e-5 {
+		t.Errorf("ball speed = %v, want %v", got, 2*base)
+	}
+
+	// Clearing a level moves on to the next, rescaling the ball's speed
+	g.state.Ball.IncreaseSpeed(1.5)
+	g.state.Paused = false
+	g.setBricks(nil)
+	g.Tick()
+	if g.state.Level != 2 || len(g.state.Bricks) != 1 || g.state.Bricks[0].Type().ID != "yellow" {
+		t.Fatalf("after the first level: Level = %d with %d bricks, want level 2 with a yellow brick", g.state.Level, len(g.state.Bricks))
+	}
+	if got := g.state.Ball.Speed(); math.Abs(float64(got-3*1.5*base)) > 1e-4 {
+		t.Errorf("ball speed = %v, want %v", got, 3*1.5*base)
+	}
+
+	// and clearing the last one wins
+	g.setBricks(nil)
+	g.Tick()
+	if !g.state.GameWon || g.level().Name != "Two" {
+		t.Errorf("GameWon = %v on %q after the last level, want true on Two", g.state.GameWon, g.level().Name)
+	}
+}
+
+func TestLevelsDirectoryMustLoad(t *testing.T) {
+	cfg := config.Default()
+	cfg.Game.LevelsDir = filepath.Join(t.TempDir(), "missing")
+	if _, err := NewHeadless(cfg, input.NewRandom(1)); err == nil {
+		t.Error("NewHeadless() error = nil for a missing levels directory")
+	}
+}
+
+func TestReplaceLevel(t *testing.T) {
+	g := newPlayingGame(t)
+	g.state.Level = 2
+	g.setBricks(g.level().Bricks())
+	before := len(g.state.Bricks)
+
+	edited, err := levels.Parse([]byte("{\"name\": \"Edited\"}\nG.G\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	g.ReplaceLevel(edited)
+	if l, i := g.CurrentLevel(); l != edited || i != 1 {
+		t.Errorf("CurrentLevel() = %q, %d, want Edited, 1", l.Name, i)
+	}
+	// The built-in levels other games play are left alone
+	if levels.Builtin()[1] == edited {
+		t.Error("ReplaceLevel() changed the built-in levels")
+	}
+	// The bricks in play stay until the level starts again
+	if len(g.state.Bricks) != before {
+		t.Errorf("%d bricks in play after ReplaceLevel(), want the %d there were", len(g.state.Bricks), before)
+	}
+	g.state.Level = 2
+	g.setBricks(g.level().Bricks())
+	if len(g.state.Bricks) != 2 {
+		t.Errorf("%d bricks once the level starts again, want the edited level's 2", len(g.state.Bricks))
+	}
+}
@@ -0,0 +1,109 @@
+package game
+
+import (
+	"breakout/internal/config"
+	"errors"
+	"strings"
+)
+
+// noticeTicks is how long a config notice stays on screen
+const noticeTicks = 3 * TickRate
+
+// Reconfigure switches the running game to a new config at the start of the
+// next tick. Values that only take effect on restart keep their current
+// setting, and their paths are returned and shown on screen.
+func (g *Game) Reconfigure(cfg config.Config) []string {
+	applied, restart := config.LiveUpdate(g.cfg, cfg)
+	g.pendingConfig = &applied
+	g.configErrors = nil
+
+	g.notice = "Config reloaded"
+	if len(restart) > 0 {
+		g.notice += ", restart to apply " + strings.Join(restart, ", ")
+	}
+	g.noticeTicks = noticeTicks
+	return restart
+}
+
+// ShowConfigError displays a config problem on screen until the next
+// successful Reconfigure. The game keeps running with its current config.
+func (g *Game) ShowConfigError(err error) {
+	var invalid *config.ValidationError
+	if !errors.As(err, &invalid) {
+		g.configErrors = []string{"Config not reloaded: " + err.Error()}
+		return
+	}
+
+	g.configErrors = []string{"Config not reloaded:"}
+	for _, problem := range invalid.Problems {
+		g.configErrors = append(g.configErrors, "  "+problem.Error())
+	}
+}
+
+// Config returns the config the game is running with
+func (g *Game) Config() config.Config {
+	return g.cfg
+}
+
+// NextConfig returns the config the game runs with from the next tick. The
+// settings screen shows it, as no ticks run while it is open, and so does
+// anything opened from there.
+func (g *Game) NextConfig() config.Config {
+	if g.pendingConfig != nil {
+		return *g.pendingConfig
+	}
+	return g.cfg
+}
+
+// applyPendingConfig switches to a config passed to Reconfigure. It runs at
+// the start of a tick, so every step of a tick sees the same values.
+func (g *Game) applyPendingConfig() {
+	if g.pendingConfig == nil {
+		return
+	}
+	old := g.cfg
+	g.cfg = *g.pendingConfig
+	g.pendingConfig = nil
+
+	g.spin = spinFromConfig(g.cfg.Game)
+	if g.cfg.Audio.Volume != old.Audio.Volume {
+		g.audio.SetVolume(g.cfg.Audio.Volume)
+	}
+
+	// A new difficulty starts a new game on it
+	if g.state.Difficulty != "" && g.cfg.Game.Difficulty != old.Game.Difficulty {
+		g.Initialize()
+		return
+	}
+
+	// A run tuned part way through no longer counts for its preset
+	if g.state.Difficulty != "" && config.DifficultyTag(g.cfg.Game) != g.state.Difficulty {
+		g.state.Difficulty = config.CustomDifficulty
+	}
+
+	// Entities only exist once the game is initialized
+	if g.state.Ball != nil && g.cfg.Game.BallBaseSpeed != old.Game.BallBaseSpeed {
+		// Keep any speed increases already earned
+		g.state.Ball.IncreaseSpeed(g.cfg.Game.BallBaseSpeed / old.Game.BallBaseSpeed)
+	}
+	if g.state.Player != nil {
+		g.state.Player.SetBaseSpeed(g.cfg.Game.PaddleBaseSpeed)
+		if g.cfg.Controls.PaddleSpeed != old.Controls.PaddleSpeed {
+			g.state.Player.SetSpeedScale(g.cfg.Controls.PaddleSpeed)
+		}
+	}
+	if g.state.Rules != nil {
+		g.state.Rules.SetRules(g.cfg.Game.Rules)
+	}
+}
+
+func (g *Game) drawConfigStatus() {
+	lines := g.configErrors
+	if len(lines) > 0 {
+		g.renderer.DrawError(lines)
+		return
+	}
+	if g.noticeTicks > 0 {
+		g.renderer.DrawNotice(g.notice)
+	}
+}
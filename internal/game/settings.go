@@ -0,0 +1,261 @@
+package game
+
+import (
+	"breakout/internal/config"
+	"breakout/internal/input"
+	"breakout/internal/renderer"
+	"fmt"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
+
+// settingsKey opens the settings screen during play and closes it again
+const settingsKey = rl.KeyEscape
+
+// SaveSettings stores settings changed on the settings screen, given as config
+// paths and values the way flags take them, and applies them to the game
+type SaveSettings func(values map[string]string) error
+
+// setting is a line on the settings screen
+type setting struct {
+	label string
+	// path is the config value the setting changes, or empty for an action
+	path string
+	// show formats the setting's current value
+	show func(cfg config.Config) string
+	// step returns the value the setting takes when changed in direction
+	// dir, -1 or 1. Settings without one are keys, changed by pressing the
+	// new key.
+	step func(cfg config.Config, dir int) string
+}
+
+// Actions at the end of the settings list
+const (
+	settingEdit = "Edit level"
+	settingBack = "Back"
+	settingQuit = "Quit"
+)
+
+var settingsList = []setting{
+	{
+		label: "Volume",
+		path:  "audio.volume",
+		show: func(cfg config.Config) string {
+			return fmt.Sprintf("%d%%", int(math.Round(float64(cfg.Audio.Volume)*100)))
+		},
+		step: func(cfg config.Config, dir int) string {
+			tenths := int(math.Round(float64(cfg.Audio.Volume)*10)) + dir
+			return fmt.Sprintf("%.1f", float32(max(0, min(10, tenths)))/10)
+		},
+	},
+	{
+		label: "Paddle speed",
+		path:  "controls.paddle_speed",
+		show: func(cfg config.Config) string {
+			return strconv.Itoa(int(cfg.Controls.PaddleSpeed))
+		},
+		step: func(cfg config.Config, dir int) string {
+			speed := max(config.MinPaddleSpeed, min(config.MaxPaddleSpeed, int(cfg.Controls.PaddleSpeed)+dir))
+			return strconv.Itoa(speed)
+		},
+	},
+	{
+		label: "Difficulty",
+		path:  "game.difficulty",
+		show: func(cfg config.Config) string {
+			if p, ok := config.LookupPreset(cfg.Game.Difficulty); ok {
+				return p.Title
+			}
+			return "Custom"
+		},
+		step: func(cfg config.Config, dir int) string {
+			names := config.PresetNames()
+			i := slices.Index(names, cfg.Game.Difficulty)
+			if i < 0 && dir < 0 {
+				i = 0
+			}
+			return names[(i+dir+len(names))%len(names)]
+		},
+	},
+	{
+		label: "Display",
+		path:  "window.fullscreen",
+		show: func(cfg config.Config) string {
+			if cfg.Window.Fullscreen {
+				return "Fullscreen"
+			}
+			return "Windowed"
+		},
+		step: func(cfg config.Config, _ int) string {
+			return strconv.FormatBool(!cfg.Window.Fullscreen)
+		},
+	},
+	keySetting("Move left", "controls.left", func(c config.ControlsConfig) string { return c.Left }),
+	keySetting("Move right", "controls.right", func(c config.ControlsConfig) string { return c.Right }),
+	keySetting("Paddle faster", "controls.faster", func(c config.ControlsConfig) string { return c.Faster }),
+	keySetting("Paddle slower", "controls.slower", func(c config.ControlsConfig) string { return c.Slower }),
+	keySetting("Serve", "controls.serve", func(c config.ControlsConfig) string { return c.Serve }),
+	keySetting("Restart", "controls.restart", func(c config.ControlsConfig) string { return c.Restart }),
+	{label: settingEdit},
+	{label: settingBack},
+	{label: settingQuit},
+}
+
+func keySetting(label, path string, key func(config.ControlsConfig) string) setting {
+	return setting{
+		label: label,
+		path:  path,
+		show: func(cfg config.Config) string {
+			return keyLabel(key(cfg.Controls))
+		},
+	}
+}
+
+// keyLabel turns a key's config name into the way it is shown, as in "Left
+// shift" for "left_shift"
+func keyLabel(name string) string {
+	if name == "" {
+		return ""
+	}
+	if len(name) == 1 {
+		return strings.ToUpper(name)
+	}
+	return strings.ToUpper(name[:1]) + strings.ReplaceAll(name[1:], "_", " ")
+}
+
+// settingsScreen lets the player change their settings during a game. It runs
+// on rendered frames rather than ticks and reads the keyboard directly, so the
+// simulation never sees it, and the game stands still while it is open.
+type settingsScreen struct {
+	save     SaveSettings
+	selected int
+	// binding is set while waiting for the new key of the selected setting
+	binding bool
+	// message replaces the selected setting's help, such as after a failed
+	// save
+	message string
+	// edit is set when the player asks to edit the level, until the game
+	// reports it
+	edit bool
+	quit bool
+}
+
+// update handles a frame of input. It reports false once the player leaves
+// the screen.
+func (s *settingsScreen) update(cfg config.Config, r *renderer.Renderer) bool {
+	if s.binding {
+		s.bindPressedKey()
+		return true
+	}
+
+	// The mouse is in playfield coordinates, see renderer.Screen
+	hovered, onItem := r.MenuItemAt(len(settingsList), rl.GetMousePosition().Y)
+	if onItem && rl.GetMouseDelta() != (rl.Vector2{}) {
+		s.selected = hovered
+	}
+
+	switch {
+	case rl.IsKeyPressed(settingsKey):
+		return false
+	case rl.IsKeyPressed(rl.KeyUp) || rl.IsKeyPressed(rl.KeyW):
+		s.move(-1)
+	case rl.IsKeyPressed(rl.KeyDown) || rl.IsKeyPressed(rl.KeyS):
+		s.move(1)
+	case rl.IsKeyPressed(rl.KeyLeft) || rl.IsKeyPressed(rl.KeyA):
+		s.change(cfg, -1)
+	case rl.IsKeyPressed(rl.KeyRight) || rl.IsKeyPressed(rl.KeyD):
+		s.change(cfg, 1)
+	case onItem && rl.IsMouseButtonPressed(rl.MouseButtonLeft):
+		s.selected = hovered
+		return s.activate(cfg)
+	case rl.IsKeyPressed(rl.KeyEnter) || rl.IsKeyPressed(rl.KeySpace):
+		return s.activate(cfg)
+	}
+	return true
+}
+
+// move selects the setting dir lines down, wrapping around
+func (s *settingsScreen) move(dir int) {
+	s.selected = (s.selected + dir + len(settingsList)) % len(settingsList)
+	s.message = ""
+}
+
+// change steps the selected setting in direction dir and saves it
+func (s *settingsScreen) change(cfg config.Config, dir int) {
+	if item := settingsList[s.selected]; item.step != nil {
+		s.apply(item.path, item.step(cfg, dir))
+	}
+}
+
+// activate does what Enter does on the selected line. It reports false if
+// that leaves the screen.
+func (s *settingsScreen) activate(cfg config.Config) bool {
+	item := settingsList[s.selected]
+	switch {
+	case item.label == settingBack:
+		return false
+	case item.label == settingEdit:
+		s.edit = true
+	case item.label == settingQuit:
+		s.quit = true
+		return false
+	case item.step != nil:
+		s.apply(item.path, item.step(cfg, 1))
+	default:
+		s.binding = true
+		s.message = "Press the new key for " + item.label + ", or Escape to cancel"
+	}
+	return true
+}
+
+// bindPressedKey binds the first key pressed this frame to the selected
+// setting
+func (s *settingsScreen) bindPressedKey() {
+	for key := rl.GetKeyPressed(); key != 0; key = rl.GetKeyPressed() {
+		if key == settingsKey {
+			s.binding = false
+			s.message = ""
+			return
+		}
+		if name := input.KeyName(key); name != "" {
+			s.binding = false
+			s.apply(settingsList[s.selected].path, name)
+			return
+		}
+	}
+}
+
+// apply saves a changed setting, showing why if that fails
+func (s *settingsScreen) apply(path, value string) {
+	s.message = ""
+	if err := s.save(map[string]string{path: value}); err != nil {
+		s.message = err.Error()
+	}
+}
+
+// draw renders the screen with cfg's values
+func (s *settingsScreen) draw(cfg config.Config, r *renderer.Renderer) {
+	lines := make([]string, len(settingsList))
+	for i, item := range settingsList {
+		lines[i] = item.label
+		if item.show != nil {
+			lines[i] += ": " + item.show(cfg)
+		}
+	}
+
+	help := s.message
+	if help == "" {
+		switch item := settingsList[s.selected]; {
+		case item.path == "":
+		case item.step != nil:
+			help = "Left and right to change"
+		default:
+			help = "Enter to change the key"
+		}
+	}
+	r.DrawMenu("Settings", lines, s.selected, help)
+}
@@ -0,0 +1,100 @@
+package game
+
+import (
+	"breakout/internal/config"
+	"errors"
+	"testing"
+)
+
+func TestSettingSteps(t *testing.T) {
+	cfg := config.Default()
+	cfg.Audio.Volume = 1
+	cfg.Controls.PaddleSpeed = config.MaxPaddleSpeed
+
+	tests := []struct {
+		label string
+		dir   int
+		want  string
+	}{
+		{"Volume", 1, "1.0"},
+		{"Volume", -1, "0.9"},
+		{"Paddle speed", 1, "5"},
+		{"Paddle speed", -1, "4"},
+		{"Difficulty", 1, "hard"},
+		{"Difficulty", -1, "easy"},
+		{"Display", 1, "true"},
+	}
+
+	for _, tt := range tests {
+		item := settingsList[settingIndex(t, tt.label)]
+		if got := item.step(cfg, tt.dir); got != tt.want {
+			t.Errorf("%s step(%d) = %q, want %q", tt.label, tt.dir, got, tt.want)
+		}
+	}
+}
+
+func TestSettingsScreenSaves(t *testing.T) {
+	var saved []map[string]string
+	s := &settingsScreen{save: func(values map[string]string) error {
+		saved = append(saved, values)
+		if values["controls.left"] == "d" {
+			return errors.New("d is already bound")
+		}
+		return nil
+	}}
+	cfg := config.Default()
+
+	s.selected = settingIndex(t, "Display")
+	if !s.activate(cfg) {
+		t.Error("activate() left the screen on a setting")
+	}
+	if len(saved) != 1 || saved[0]["window.fullscreen"] != "true" {
+		t.Errorf("saved %v, want window.fullscreen true", saved)
+	}
+
+	s.selected = settingIndex(t, "Move left")
+	s.activate(cfg)
+	if !s.binding {
+		t.Fatal("activate() on a key is not waiting for the new key")
+	}
+	s.binding = false
+	s.apply("controls.left", "d")
+	if s.message != "d is already bound" {
+		t.Errorf("message = %q, want the save error", s.message)
+	}
+
+	s.move(1)
+	if s.message != "" {
+		t.Errorf("message = %q after moving on, want none", s.message)
+	}
+
+	s.selected = settingIndex(t, settingQuit)
+	if s.activate(cfg) || !s.quit {
+		t.Error("Quit did not leave the screen and ask to quit")
+	}
+}
+
+func TestSettingsChangeDifficultyRestarts(t *testing.T) {
+	g := newPlayingGame(t)
+	g.state.Score = 40
+
+	cfg := g.cfg
+	cfg.Game.Difficulty = "hard"
+	g.Reconfigure(cfg)
+	g.Tick()
+
+	if g.state.Score != 0 {
+		t.Errorf("Score = %d after changing the difficulty, want a new game", g.state.Score)
+	}
+}
+
+func settingIndex(t *testing.T, label string) int {
+	t.Helper()
+	for i, item := range settingsList {
+		if item.label == label {
+			return i
+		}
+	}
+	t.Fatalf("no setting %q", label)
+	return 0
+}
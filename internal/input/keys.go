@@ -0,0 +1,14 @@
+package input
+
+import "breakout/internal/config"
+
+// KeyByName returns the key with the given config name, such as "a" or
+// "left_shift"
+func KeyByName(name string) (int32, bool) {
+	return config.KeyCode(name)
+}
+
+// KeyName returns the config name of a key, or "" if it cannot be bound
+func KeyName(key int32) string {
+	return config.KeyName(key)
+}
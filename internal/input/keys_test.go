@@ -0,0 +1,45 @@
+package input
+
+import (
+	"testing"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
+
+func TestKeyNamesMatchRaylib(t *testing.T) {
+	keys := map[string]int32{
+		"space":         rl.KeySpace,
+		"enter":         rl.KeyEnter,
+		"tab":           rl.KeyTab,
+		"backspace":     rl.KeyBackspace,
+		"left":          rl.KeyLeft,
+		"right":         rl.KeyRight,
+		"up":            rl.KeyUp,
+		"down":          rl.KeyDown,
+		"left_shift":    rl.KeyLeftShift,
+		"right_shift":   rl.KeyRightShift,
+		"left_control":  rl.KeyLeftControl,
+		"right_control": rl.KeyRightControl,
+		"left_alt":      rl.KeyLeftAlt,
+		"right_alt":     rl.KeyRightAlt,
+		"comma":         rl.KeyComma,
+		"period":        rl.KeyPeriod,
+		"slash":         rl.KeySlash,
+		"semicolon":     rl.KeySemicolon,
+		"a":             rl.KeyA,
+		"z":             rl.KeyZ,
+		"0":             rl.KeyZero,
+		"9":             rl.KeyNine,
+	}
+	for name, want := range keys {
+		if got, ok := KeyByName(name); !ok || got != want {
+			t.Errorf("KeyByName(%q) = %d, %v, want %d", name, got, ok, want)
+		}
+		if got := KeyName(want); got != name {
+			t.Errorf("KeyName(%d) = %q, want %q", want, got, name)
+		}
+	}
+	if _, ok := KeyByName("escape"); ok {
+		t.Error("KeyByName(\"escape\") found a key, want escape unbindable")
+	}
+}
@@ -0,0 +1,59 @@
+package input
+
+import (
+	"math/rand"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
+
+const (
+	randomMinHoldFrames = 10
+	randomMaxHoldFrames = 70
+	randomPressChance   = 120 // one press every N frames on average
+)
+
+var (
+	randomHeldKeys    = []int32{0, rl.KeyA, rl.KeyD}
+	randomPressedKeys = []int32{rl.KeySpace, rl.KeyW, rl.KeyS}
+)
+
+// Random is a seeded source that plays like a restless player. The same seed
+// always produces the same sequence of keys.
+type Random struct {
+	rng      *rand.Rand
+	held     int32
+	holdLeft int
+	pressed  int32
+}
+
+// NewRandom creates a random source from the given seed
+func NewRandom(seed int64) *Random {
+	return &Random{
+		rng: rand.New(rand.NewSource(seed)),
+	}
+}
+
+// Poll picks the keys for the next frame
+func (r *Random) Poll() {
+	r.pressed = 0
+
+	if r.holdLeft <= 0 {
+		r.held = randomHeldKeys[r.rng.Intn(len(randomHeldKeys))]
+		r.holdLeft = randomMinHoldFrames + r.rng.Intn(randomMaxHoldFrames-randomMinHoldFrames)
+	}
+	r.holdLeft--
+
+	if r.rng.Intn(randomPressChance) == 0 {
+		r.pressed = randomPressedKeys[r.rng.Intn(len(randomPressedKeys))]
+	}
+}
+
+// IsKeyDown reports whether the key is held
+func (r *Random) IsKeyDown(key int32) bool {
+	return key != 0 && (key == r.held || key == r.pressed)
+}
+
+// IsKeyPressed reports whether the key was pressed this frame
+func (r *Random) IsKeyPressed(key int32) bool {
+	return key != 0 && key == r.pressed
+}
@@ -0,0 +1,33 @@
+package input
+
+import (
+	"testing"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
+
+func TestRandomIsDeterministic(t *testing.T) {
+	keys := []int32{rl.KeyA, rl.KeyD, rl.KeyW, rl.KeyS, rl.KeySpace}
+	a := NewRandom(42)
+	b := NewRandom(42)
+
+	for frame := 0; frame < 1000; frame++ {
+		a.Poll()
+		b.Poll()
+		for _, key := range keys {
+			if a.IsKeyDown(key) != b.IsKeyDown(key) || a.IsKeyPressed(key) != b.IsKeyPressed(key) {
+				t.Fatalf("Sources with the same seed diverged at frame %d on key %d", frame, key)
+			}
+		}
+	}
+}
+
+func TestRandomPressedKeyIsAlsoDown(t *testing.T) {
+	r := NewRandom(7)
+	for frame := 0; frame < 1000; frame++ {
+		r.Poll()
+		if r.pressed != 0 && !r.IsKeyDown(r.pressed) {
+			t.Fatalf("Key %d pressed at frame %d should also be down", r.pressed, frame)
+		}
+	}
+}
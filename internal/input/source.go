@@ -0,0 +1,94 @@
+package input
+
+import rl "github.com/gen2brain/raylib-go/raylib"
+
+// Keys lists every key the game reads
+var Keys = []int32{
+	rl.KeyA,
+	rl.KeyD,
+	rl.KeyW,
+	rl.KeyS,
+	rl.KeySpace,
+	rl.KeyR,
+}
+
+// Source provides the keyboard state the game reads each tick
+type Source interface {
+	// Poll advances the source to the next tick
+	Poll()
+	// IsKeyDown reports whether the key is held during the current tick
+	IsKeyDown(key int32) bool
+	// IsKeyPressed reports whether the key went down during the current tick
+	IsKeyPressed(key int32) bool
+}
+
+// Finite is a source that runs out, such as a recording. The game stops
+// ticking once it is done.
+type Finite interface {
+	Source
+	// Done reports whether every tick of input has been used
+	Done() bool
+}
+
+// Keyboard reads input from the raylib window. Raylib reports key presses per
+// rendered frame, while the game runs at a fixed tick rate, so presses are
+// latched by Capture and handed to exactly one tick by Poll.
+//
+// The game reads the keys in Keys. Bindings let the player use other keys
+// for them, so the game and replays only ever see the keys in Keys.
+type Keyboard struct {
+	bindings    map[int32]int32
+	down        map[int32]bool
+	pressed     map[int32]bool
+	pendingDown map[int32]bool
+	pending     map[int32]bool
+}
+
+// NewKeyboard creates a source backed by the raylib keyboard
+func NewKeyboard() *Keyboard {
+	return &Keyboard{
+		down:        make(map[int32]bool),
+		pressed:     make(map[int32]bool),
+		pendingDown: make(map[int32]bool),
+		pending:     make(map[int32]bool),
+	}
+}
+
+// Bind makes the key the game reads as each key of bindings follow the
+// player's key it maps to. Keys left out follow themselves.
+func (k *Keyboard) Bind(bindings map[int32]int32) {
+	k.bindings = bindings
+}
+
+// Capture samples the raylib keyboard. Call it once per rendered frame.
+func (k *Keyboard) Capture() {
+	for _, key := range Keys {
+		physical, ok := k.bindings[key]
+		if !ok {
+			physical = key
+		}
+		k.pendingDown[key] = rl.IsKeyDown(physical)
+		if rl.IsKeyPressed(physical) {
+			k.pending[key] = true
+		}
+	}
+}
+
+// Poll hands the keys captured since the previous tick to the next one
+func (k *Keyboard) Poll() {
+	for _, key := range Keys {
+		k.down[key] = k.pendingDown[key] || k.pending[key]
+		k.pressed[key] = k.pending[key]
+		k.pending[key] = false
+	}
+}
+
+// IsKeyDown reports whether the key is held
+func (k *Keyboard) IsKeyDown(key int32) bool {
+	return k.down[key]
+}
+
+// IsKeyPressed reports whether the key was pressed since the previous tick
+func (k *Keyboard) IsKeyPressed(key int32) bool {
+	return k.pressed[key]
+}
@@ -0,0 +1,359 @@
+// Package levels reads the levels a game is played through. A level is a text
+// file: a JSON object with the level's settings, followed by its brick grid
+// drawn in ASCII, one line per row of bricks and one character per brick.
+//
+//	{
+//	  "name": "Gap",
+//	  "ball_speed": 1.2,
+//	  "par": 120,
+//	  "background": "#101820"
+//	}
+//	RRRRRR
+//	OO..OO
+//	YYYYYY
+//
+// Each character is the symbol of a brick type, see the bricks package, or
+// bricks.Empty for a cell without a brick. The built-in levels are read from
+// the builtin directory.
+package levels
+
+import (
+	"breakout/internal/bricks"
+	"breakout/internal/config"
+	"breakout/internal/entities"
+	"bytes"
+	"embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"image/color"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// Extension is the file extension of level files. Other files in a levels
+// directory are ignored.
+const Extension = ".level"
+
+// Level is a brick grid and the settings it is played with
+type Level struct {
+	Name string
+	// BallSpeed scales the ball's speed while the level is played
+	BallSpeed float32
+	// Par is the score a good run through the level reaches
+	Par        int32
+	Background color.RGBA
+	// Grid holds the type of every brick, row by row from the top, with nil
+	// for empty cells. Every row is the same length.
+	Grid [][]*bricks.Type
+}
+
+// header is a level's settings as they are written in its file
+type header struct {
+	Name       string  `json:"name"`
+	BallSpeed  float32 `json:"ball_speed"`
+	Par        int32   `json:"par"`
+	Background string  `json:"background"`
+}
+
+//go:embed builtin/*.level
+var builtinFS embed.FS
+
+// Builtin returns the levels that ship with the game
+var Builtin = sync.OnceValue(func() []*Level {
+	levels, err := readDir(builtinFS, "builtin", "builtin")
+	if err != nil {
+		panic("levels: built-in levels: " + err.Error())
+	}
+	return levels
+})
+
+// Load reads the levels in dir in the order of their file names, or returns
+// the built-in levels if dir is empty
+func Load(dir string) ([]*Level, error) {
+	if dir == "" {
+		return Builtin(), nil
+	}
+	return readDir(os.DirFS(dir), ".", dir)
+}
+
+// Files returns the names of the level files in dir, in the order Load reads
+// them, or those of the built-in levels if dir is empty
+func Files(dir string) ([]string, error) {
+	if dir == "" {
+		return listDir(builtinFS, "builtin", "builtin")
+	}
+	return listDir(os.DirFS(dir), ".", dir)
+}
+
+// CopyBuiltin writes the built-in level files to dir, creating it if needed, so
+// they can be changed there. Files already in dir are kept, such as a built-in
+// level saved there after being edited.
+func CopyBuiltin(dir string) error {
+	names, err := Files("")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	for _, name := range names {
+		data, err := fs.ReadFile(builtinFS, path.Join("builtin", name))
+		if err != nil {
+			return err
+		}
+		f, err := os.OpenFile(filepath.Join(dir, name), os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o644)
+		if errors.Is(err, fs.ErrExist) {
+			continue
+		} else if err != nil {
+			return err
+		}
+		_, err = f.Write(data)
+		if closeErr := f.Close(); err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// readDir reads every level file in dir of fsys. Errors name the files as if
+// dir were shown, so they point at the file on disk.
+func readDir(fsys fs.FS, dir, shown string) ([]*Level, error) {
+	names, err := listDir(fsys, dir, shown)
+	if err != nil {
+		return nil, err
+	}
+
+	var levels []*Level
+	var problems []error
+	for _, name := range names {
+		data, err := fs.ReadFile(fsys, path.Join(dir, name))
+		if err == nil {
+			var l *Level
+			if l, err = Parse(data); err == nil {
+				if l.Name == "" {
+					l.Name = strings.TrimSuffix(name, Extension)
+				}
+				levels = append(levels, l)
+			}
+		}
+		if err != nil {
+			problems = append(problems, fmt.Errorf("%s: %w", path.Join(shown, name), err))
+		}
+	}
+	if err := errors.Join(problems...); err != nil {
+		return nil, err
+	}
+	return levels, nil
+}
+
+// listDir returns the names of the level files in dir of fsys, sorted, failing
+// if there are none
+func listDir(fsys fs.FS, dir, shown string) ([]string, error) {
+	entries, err := fs.ReadDir(fsys, dir)
+	if err != nil {
+		// The error names dir within fsys, not the directory on disk
+		var pathErr *fs.PathError
+		if errors.As(err, &pathErr) {
+			err = pathErr.Err
+		}
+		return nil, fmt.Errorf("%s: %w", shown, err)
+	}
+
+	var names []string
+	for _, e := range entries {
+		if !e.IsDir() && path.Ext(e.Name()) == Extension {
+			names = append(names, e.Name())
+		}
+	}
+	if len(names) == 0 {
+		return nil, fmt.Errorf("%s: no %s files", shown, Extension)
+	}
+	return names, nil
+}
+
+// Parse reads a level file. Every problem with it is reported at once, with
+// the line it is on.
+func Parse(data []byte) (*Level, error) {
+	h := header{BallSpeed: 1, Background: "#000000"}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&h); err != nil {
+		var syntax *json.SyntaxError
+		if errors.As(err, &syntax) {
+			return nil, fmt.Errorf("line %d: %w", lineAt(data, syntax.Offset), err)
+		}
+		return nil, err
+	}
+
+	var problems []error
+	fail := func(line int, format string, args ...any) {
+		problems = append(problems, fmt.Errorf("line %d: %s", line, fmt.Sprintf(format, args...)))
+	}
+
+	l := &Level{Name: h.Name, BallSpeed: h.BallSpeed, Par: h.Par}
+	var err error
+	if l.Background, err = bricks.ParseColor(h.Background); err != nil {
+		problems = append(problems, fmt.Errorf("background: %w", err))
+	}
+	if !(h.BallSpeed > 0) {
+		problems = append(problems, fmt.Errorf("ball_speed must be a positive number, got %v", h.BallSpeed))
+	}
+	if h.Par < 0 {
+		problems = append(problems, fmt.Errorf("par must not be negative, got %d", h.Par))
+	}
+
+	// The grid starts on the line after the settings end
+	offset := int(dec.InputOffset())
+	rest, _, _ := bytes.Cut(data[offset:], []byte("\n"))
+	if len(bytes.TrimSpace(rest)) > 0 {
+		fail(lineAt(data, int64(offset)), "the brick grid must start on a new line")
+	}
+	first := lineAt(data, int64(offset)) + 1
+
+	lines := strings.Split(string(data[min(offset+len(rest)+1, len(data)):]), "\n")
+	for i := range lines {
+		lines[i] = strings.TrimRight(lines[i], " \t\r")
+	}
+	// Skip blank lines around the grid, but not inside it, where they would
+	// silently move every row below
+	for len(lines) > 0 && lines[0] == "" {
+		lines = lines[1:]
+		first++
+	}
+	for len(lines) > 0 && lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+
+	breakable := false
+	for i, text := range lines {
+		line := first + i
+		switch {
+		case text == "":
+			fail(line, "blank line in the brick grid, write an empty row as %q", strings.Repeat(string(bricks.Empty), len(lines[0])))
+			continue
+		case len(text) != len(lines[0]):
+			fail(line, "row is %d bricks wide, the first row is %d", len(text), len(lines[0]))
+		}
+
+		row := make([]*bricks.Type, len(text))
+		for col := range len(text) {
+			if text[col] == bricks.Empty {
+				continue
+			}
+			t, ok := bricks.Default().LookupSymbol(text[col])
+			if !ok {
+				fail(line, "column %d: unknown brick %q", col+1, text[col])
+				continue
+			}
+			row[col] = t
+			breakable = breakable || !t.Indestructible
+		}
+		l.Grid = append(l.Grid, row)
+	}
+
+	switch cols, rows := l.Size(); {
+	case rows == 0:
+		problems = append(problems, errors.New("the level has no brick grid"))
+	case cols > config.MaxBrickColumns:
+		problems = append(problems, fmt.Errorf("%d bricks do not fit across the playfield, at most %d do", cols, config.MaxBrickColumns))
+	case rows > config.MaxBrickRows:
+		problems = append(problems, fmt.Errorf("%d rows of bricks do not fit above the paddle, at most %d do", rows, config.MaxBrickRows))
+	case !breakable:
+		problems = append(problems, errors.New("the level has no bricks to break"))
+	}
+
+	if err := errors.Join(problems...); err != nil {
+		return nil, err
+	}
+	return l, nil
+}
+
+// Blank returns a level with an empty grid of the given size
+func Blank(name string, cols, rows int32) *Level {
+	l := &Level{Name: name, BallSpeed: 1, Background: color.RGBA{A: 0xff}}
+	for range rows {
+		l.Grid = append(l.Grid, make([]*bricks.Type, cols))
+	}
+	return l
+}
+
+// Format writes the level in the level file format, such that Parse reads it
+// back the same
+func (l *Level) Format() []byte {
+	data, err := json.MarshalIndent(header{
+		Name:       l.Name,
+		BallSpeed:  l.BallSpeed,
+		Par:        l.Par,
+		Background: bricks.FormatColor(l.Background),
+	}, "", "  ")
+	if err != nil {
+		panic(err)
+	}
+
+	var b bytes.Buffer
+	b.Write(data)
+	b.WriteByte('\n')
+	for _, row := range l.Grid {
+		for _, t := range row {
+			if t == nil {
+				b.WriteByte(bricks.Empty)
+			} else {
+				b.WriteByte(t.Symbol)
+			}
+		}
+		b.WriteByte('\n')
+	}
+	return b.Bytes()
+}
+
+// Clone returns a copy of the level that can be changed without changing l
+func (l *Level) Clone() *Level {
+	c := *l
+	c.Grid = make([][]*bricks.Type, len(l.Grid))
+	for i, row := range l.Grid {
+		c.Grid[i] = slices.Clone(row)
+	}
+	return &c
+}
+
+// lineAt returns the line number of the byte at offset
+func lineAt(data []byte, offset int64) int {
+	return bytes.Count(data[:min(int(offset), len(data))], []byte("\n")) + 1
+}
+
+// Size returns the number of columns and rows in the level's grid
+func (l *Level) Size() (cols, rows int32) {
+	if len(l.Grid) == 0 {
+		return 0, 0
+	}
+	return int32(len(l.Grid[0])), int32(len(l.Grid))
+}
+
+// Layout returns the grid the level's bricks are laid out on
+func (l *Level) Layout() entities.BrickLayout {
+	return entities.NewBrickLayout(l.Size())
+}
+
+// Bricks creates the level's bricks, column by column from the left
+func (l *Level) Bricks() []*entities.Brick {
+	layout := l.Layout()
+	cols, rows := l.Size()
+	out := make([]*entities.Brick, 0, cols*rows)
+	for col := range cols {
+		for row := range rows {
+			if t := l.Grid[row][col]; t != nil {
+				out = append(out, entities.NewBrick(layout, col, row, t))
+			}
+		}
+	}
+	return out
+}
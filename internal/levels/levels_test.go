@@ -0,0 +1,252 @@
+package levels
+
+import (
+	"breakout/internal/bricks"
+	"image/color"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+)
+
+const gap = `{
+  "name": "Gap",
+  "ball_speed": 1.2,
+  "par": 120,
+  "background": "#101820"
+}
+RRRRRR
+OO..OO
+
+`
+
+func TestBuiltin(t *testing.T) {
+	levels := Builtin()
+
+	var names []string
+	for _, l := range levels {
+		names = append(names, l.Name)
+	}
+	if want := []string{"Classic", "Pyramid", "Checkers"}; !slices.Equal(names, want) {
+		t.Fatalf("Builtin() names = %v, want %v", names, want)
+	}
+
+	// The classic wall has two rows of each colour, red at the top
+	classic := levels[0]
+	if cols, rows := classic.Size(); cols != 14 || rows != 8 {
+		t.Errorf("Classic Size() = %d, %d, want 14, 8", cols, rows)
+	}
+	for row, want := range []string{"red", "red", "orange", "orange", "green", "green", "yellow", "yellow"} {
+		for col, kind := range classic.Grid[row] {
+			if kind == nil || kind.ID != want {
+				t.Fatalf("Classic brick at col %d, row %d = %v, want %s", col, row, kind, want)
+			}
+		}
+	}
+	if got := len(classic.Bricks()); got != 14*8 {
+		t.Errorf("Classic Bricks() = %d bricks, want %d", got, 14*8)
+	}
+}
+
+func TestParse(t *testing.T) {
+	l, err := Parse([]byte(gap))
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	if l.Name != "Gap" || l.BallSpeed != 1.2 || l.Par != 120 {
+		t.Errorf("Parse() = %q, %v, %d, want Gap, 1.2, 120", l.Name, l.BallSpeed, l.Par)
+	}
+	if l.Background != (color.RGBA{R: 0x10, G: 0x18, B: 0x20, A: 0xff}) {
+		t.Errorf("Background = %v, want #101820", l.Background)
+	}
+	if cols, rows := l.Size(); cols != 6 || rows != 2 {
+		t.Errorf("Size() = %d, %d, want 6, 2", cols, rows)
+	}
+	if l.Grid[1][2] != nil || l.Grid[1][1] != bricks.Default().MustLookup("orange") {
+		t.Errorf("row 1 = %v, want orange bricks around a gap", l.Grid[1])
+	}
+
+	// Empty cells make no bricks, and bricks are laid out on the level's grid
+	got := l.Bricks()
+	if len(got) != 10 {
+		t.Fatalf("Bricks() = %d bricks, want 10", len(got))
+	}
+	layout := l.Layout()
+	if layout.PerRow != 6 || layout.PerCol != 2 {
+		t.Errorf("Layout() = %+v, want 6 by 2", layout)
+	}
+}
+
+func TestFormatRoundTrips(t *testing.T) {
+	for _, want := range append([]*Level{mustParse(t, gap)}, Builtin()...) {
+		got, err := Parse(want.Format())
+		if err != nil {
+			t.Fatalf("Parse(Format()) of %s error = %v", want.Name, err)
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("Parse(Format()) = %+v, want %+v", got, want)
+		}
+	}
+}
+
+func TestClone(t *testing.T) {
+	l := mustParse(t, gap)
+	c := l.Clone()
+	c.Grid[0][0] = nil
+	c.Name = "Changed"
+	if l.Grid[0][0] == nil || l.Name != "Gap" {
+		t.Error("changing a clone changed the original")
+	}
+}
+
+func TestParseDefaults(t *testing.T) {
+	l, err := Parse([]byte("{}\nY\n"))
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	if l.BallSpeed != 1 || l.Par != 0 || l.Background != (color.RGBA{A: 0xff}) {
+		t.Errorf("Parse() = %+v, want ball speed 1, no par and a black background", l)
+	}
+}
+
+func TestParseRejects(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		want []string
+	}{
+		{"Unknown setting", "{\"speed\": 2}\nR\n", []string{`unknown field "speed"`}},
+		{"Bad JSON", "{\n  \"name\": \"x\",,\n}\nR\n", []string{"line 2"}},
+		{"Zero ball speed", "{\"ball_speed\": 0}\nR\n", []string{"ball_speed must be a positive number"}},
+		{"Negative par", "{\"par\": -5}\nR\n", []string{"par must not be negative"}},
+		{"Named background", "{\"background\": \"blue\"}\nR\n", []string{"background: color must be written as #rrggbb"}},
+		{"Grid after the settings", "{} RR\nRR\n", []string{"line 1: the brick grid must start on a new line"}},
+		{"Unknown brick", "{}\nRRR\nRxR\n", []string{"line 3: column 2: unknown brick 'x'"}},
+		{"Ragged rows", "{}\nRRR\nRR\n", []string{"line 3: row is 2 bricks wide, the first row is 3"}},
+		{"Blank line", "{}\nRRR\n\nRRR\n", []string{"line 3: blank line in the brick grid"}},
+		{"No grid", "{}\n", []string{"no brick grid"}},
+		{"No bricks", "{}\n...\n", []string{"no bricks"}},
+		{"Only steel", "{}\nX.X\n", []string{"no bricks to break"}},
+		{"Too wide", "{}\n" + strings.Repeat("R", 153) + "\n", []string{"153 bricks do not fit across the playfield, at most 152 do"}},
+		{"Too tall", "{}\n" + strings.Repeat("R\n", 48), []string{"48 rows of bricks do not fit above the paddle, at most 47 do"}},
+		{
+			"Every problem at once",
+			"{\"ball_speed\": -1}\nRRR\nRQ\n",
+			[]string{"ball_speed", "line 3: row is 2 bricks wide", "line 3: column 2: unknown brick 'Q'"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse([]byte(tt.data))
+			if err == nil {
+				t.Fatalf("Parse() error = nil, want %q", tt.want)
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(err.Error(), want) {
+					t.Errorf("Parse() error = %q, want it to mention %q", err, want)
+				}
+			}
+		})
+	}
+}
+
+func TestLoad(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "02-second.level"), "{\"name\": \"Second\"}\nY\n")
+	writeFile(t, filepath.Join(dir, "01-first.level"), "{}\nR\n")
+	writeFile(t, filepath.Join(dir, "notes.txt"), "not a level")
+
+	levels, err := Load(dir)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	var names []string
+	for _, l := range levels {
+		names = append(names, l.Name)
+	}
+	// A level without a name is named after its file
+	if want := []string{"01-first", "Second"}; !slices.Equal(names, want) {
+		t.Errorf("Load() names = %v, want %v", names, want)
+	}
+
+	if got, err := Load(""); err != nil || len(got) != len(Builtin()) {
+		t.Errorf("Load(\"\") = %d levels, %v, want the built-in levels", len(got), err)
+	}
+
+	files, err := Files(dir)
+	if want := []string{"01-first.level", "02-second.level"}; err != nil || !slices.Equal(files, want) {
+		t.Errorf("Files() = %v, %v, want %v", files, err, want)
+	}
+	if files, err := Files(""); err != nil || len(files) != len(Builtin()) {
+		t.Errorf("Files(\"\") = %v, %v, want one per built-in level", files, err)
+	}
+}
+
+func TestCopyBuiltin(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "levels")
+	files, err := Files("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	// An edited copy of the first built-in level
+	edited := "{\"name\": \"Edited\"}\nR\n"
+	writeFile(t, filepath.Join(dir, files[0]), edited)
+
+	if err := CopyBuiltin(dir); err != nil {
+		t.Fatalf("CopyBuiltin() error = %v", err)
+	}
+	copied, err := Load(dir)
+	if err != nil || len(copied) != len(Builtin()) {
+		t.Fatalf("Load() of the copies = %d levels, %v, want the built-in levels", len(copied), err)
+	}
+	if copied[0].Name != "Edited" {
+		t.Errorf("first level = %q, want the edited file kept", copied[0].Name)
+	}
+	for i, l := range copied[1:] {
+		if want := Builtin()[i+1]; !reflect.DeepEqual(l, want) {
+			t.Errorf("copied level %d = %+v, want %+v", i+2, l, want)
+		}
+	}
+}
+
+func TestLoadRejects(t *testing.T) {
+	empty := t.TempDir()
+	if _, err := Load(empty); err == nil || !strings.Contains(err.Error(), "no .level files") {
+		t.Errorf("Load() of an empty directory error = %v, want no level files", err)
+	}
+
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "01.level"), "{}\nR\n")
+	writeFile(t, filepath.Join(dir, "02.level"), "{}\nZ\n")
+	_, err := Load(dir)
+	if want := filepath.Join(dir, "02.level") + ": line 2"; err == nil || !strings.Contains(err.Error(), want) {
+		t.Errorf("Load() error = %v, want it to point at %s", err, want)
+	}
+
+	if _, err := Load(filepath.Join(dir, "missing")); err == nil {
+		t.Error("Load() of a missing directory error = nil")
+	}
+}
+
+func mustParse(t *testing.T, data string) *Level {
+	t.Helper()
+	l, err := Parse([]byte(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return l
+}
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -2,22 +2,316 @@ package physics
 
 import (
 	"breakout/internal/types"
+	"math"
 
 	rl "github.com/gen2brain/raylib-go/raylib"
 )
 
 // Engine handles collision detection
-type Engine struct{}
+type Engine struct {
+	static *Grid
+}
 
 // New creates a new physics engine
 func New() *Engine {
-	return &Engine{}
+	return &Engine{
+		static: NewGrid(DefaultCellSize),
+	}
+}
+
+// AddStatic indexes a static shape, such as a brick, under the given id
+func (e *Engine) AddStatic(id int, bounds types.Rectangle) {
+	e.static.Insert(id, bounds)
+}
+
+// RemoveStatic drops the static shape with the given id from the index
+func (e *Engine) RemoveStatic(id int) {
+	e.static.Remove(id)
+}
+
+// ClearStatic drops every static shape from the index
+func (e *Engine) ClearStatic() {
+	e.static.Clear()
+}
+
+// QueryStatic returns the ids of the static shapes touching area, in ascending
+// order
+func (e *Engine) QueryStatic(area types.Rectangle) []int {
+	return e.static.Query(area)
+}
+
+// SweptArea returns the area a rectangle covers while moving by delta
+func SweptArea(a types.Rectangle, delta types.Vector2) types.Rectangle {
+	x0, x1 := min(a.X, a.X+delta.X), max(a.X, a.X+delta.X)
+	y0, y1 := min(a.Y, a.Y+delta.Y), max(a.Y, a.Y+delta.Y)
+	return types.Rectangle{X: x0, Y: y0, Width: x1 - x0 + a.Width, Height: y1 - y0 + a.Height}
+}
+
+// Contact describes the first touch between a moving and a static shape
+type Contact struct {
+	// Time is the fraction of the motion, in [0, 1], completed at first touch
+	Time float32
+	// Normal is the unit normal of the surface that was hit, pointing towards
+	// the moving shape
+	Normal types.Vector2
+}
+
+// Penetration describes how deep a circle sits inside a rectangle
+type Penetration struct {
+	// Normal is the unit direction that moves the circle out fastest
+	Normal types.Vector2
+	// Depth is how far the circle must move along Normal to be clear
+	Depth float32
+}
+
+// Separation describes how to move a shape clear of the shapes it overlaps
+type Separation struct {
+	// Correction is the translation that moves the shape out of penetration
+	Correction types.Vector2
+	// Normal combines the normals of every surface pushed against, see
+	// CombineNormals
+	Normal types.Vector2
+	// Hits holds the indices of the overlapped shapes
+	Hits []int
 }
 
 // CheckCollision checks if two collidable objects are colliding
 func (e *Engine) CheckCollision(a, b types.Collidable) bool {
 	boundsA := a.GetBounds()
 	boundsB := b.GetBounds()
-	
+
 	return rl.CheckCollisionRecs(boundsA.ToRaylib(), boundsB.ToRaylib())
-}
\ No newline at end of file
+}
+
+// CircleRect tests a circle against a rectangle. The normal points from the
+// closest point on the rectangle towards the circle's centre, so a circle
+// touching a corner gets a diagonal normal rather than a face normal.
+func (e *Engine) CircleRect(c types.Circle, r types.Rectangle) (Penetration, bool) {
+	closest := types.Vector2{
+		X: max(r.X, min(r.X+r.Width, c.Center.X)),
+		Y: max(r.Y, min(r.Y+r.Height, c.Center.Y)),
+	}
+	diff := types.Vector2{X: c.Center.X - closest.X, Y: c.Center.Y - closest.Y}
+	distSq := dot(diff, diff)
+	if distSq >= c.Radius*c.Radius {
+		return Penetration{}, false
+	}
+
+	if distSq > 0 {
+		dist := float32(math.Sqrt(float64(distSq)))
+		return Penetration{
+			Normal: types.Vector2{X: diff.X / dist, Y: diff.Y / dist},
+			Depth:  c.Radius - dist,
+		}, true
+	}
+
+	// The centre is inside the rectangle, leave through the nearest face
+	f, _ := penetration(c.Bounds(), r)
+	return Penetration{Normal: f.normal, Depth: f.depth}, true
+}
+
+// Sweep moves circle c by delta and reports the first contact with the static
+// rectangle b. Contacts are only reported while c moves towards b's surface,
+// so a circle moving away from b is never stopped by it. If c already overlaps
+// b, the contact is at time 0 with the penetration normal.
+func (e *Engine) Sweep(c types.Circle, delta types.Vector2, b types.Rectangle) (Contact, bool) {
+	if p, ok := e.CircleRect(c, b); ok {
+		if dot(delta, p.Normal) >= 0 {
+			return Contact{}, false
+		}
+		return Contact{Time: 0, Normal: p.Normal}, true
+	}
+
+	// Sweeping a circle against a rectangle is a ray cast from the circle's
+	// centre against the rectangle grown by the radius with rounded corners:
+	// four flat faces and four quarter circles
+	best := Contact{Time: float32(math.Inf(1))}
+	consider := func(t float32, normal types.Vector2) {
+		if t >= 0 && t <= 1 && t < best.Time && dot(delta, normal) < 0 {
+			best = Contact{Time: t, Normal: normal}
+		}
+	}
+
+	p, r := c.Center, c.Radius
+	left, right := b.X, b.X+b.Width
+	top, bottom := b.Y, b.Y+b.Height
+
+	if delta.Y != 0 {
+		for _, face := range []struct{ y, ny float32 }{{top - r, -1}, {bottom + r, 1}} {
+			t := (face.y - p.Y) / delta.Y
+			if x := p.X + delta.X*t; x >= left && x <= right {
+				consider(t, types.Vector2{Y: face.ny})
+			}
+		}
+	}
+	if delta.X != 0 {
+		for _, face := range []struct{ x, nx float32 }{{left - r, -1}, {right + r, 1}} {
+			t := (face.x - p.X) / delta.X
+			if y := p.Y + delta.Y*t; y >= top && y <= bottom {
+				consider(t, types.Vector2{X: face.nx})
+			}
+		}
+	}
+
+	for _, corner := range []types.Vector2{{X: left, Y: top}, {X: right, Y: top}, {X: left, Y: bottom}, {X: right, Y: bottom}} {
+		t, ok := rayCircle(p, delta, corner, r)
+		if !ok {
+			continue
+		}
+		hit := types.Vector2{X: p.X + delta.X*t, Y: p.Y + delta.Y*t}
+		inCornerX := (corner.X == left && hit.X < left) || (corner.X == right && hit.X > right)
+		inCornerY := (corner.Y == top && hit.Y < top) || (corner.Y == bottom && hit.Y > bottom)
+		if inCornerX && inCornerY {
+			consider(t, types.Vector2{X: (hit.X - corner.X) / r, Y: (hit.Y - corner.Y) / r})
+		}
+	}
+
+	if math.IsInf(float64(best.Time), 1) {
+		return Contact{}, false
+	}
+	return best, true
+}
+
+// Separate finds every rectangle in others that circle c overlaps and a
+// correction that pushes c out of all of them. Each overlap pushes along its
+// penetration normal. When c is pinched, pushed from both sides along one
+// axis, the pinching overlaps push along the other axis instead.
+func (e *Engine) Separate(c types.Circle, others []types.Rectangle) (Separation, bool) {
+	var sep Separation
+	var pushes []face
+	var alts []face
+	for i, b := range others {
+		p, ok := e.CircleRect(c, b)
+		if !ok {
+			continue
+		}
+
+		// The alternative push is along whichever axis the normal leans
+		// away from, using the circle's bounding box
+		horizontal, vertical := axisPenetration(c.Bounds(), b)
+		alt := vertical
+		if math.Abs(float64(p.Normal.Y)) > math.Abs(float64(p.Normal.X)) {
+			alt = horizontal
+		}
+
+		sep.Hits = append(sep.Hits, i)
+		pushes = append(pushes, face{p.Normal, p.Depth})
+		alts = append(alts, alt)
+	}
+	if len(pushes) == 0 {
+		return sep, false
+	}
+
+	var pinchedX, pinchedY [2]bool
+	for _, p := range pushes {
+		pinchedX[0] = pinchedX[0] || p.normal.X < -axisEpsilon
+		pinchedX[1] = pinchedX[1] || p.normal.X > axisEpsilon
+		pinchedY[0] = pinchedY[0] || p.normal.Y < -axisEpsilon
+		pinchedY[1] = pinchedY[1] || p.normal.Y > axisEpsilon
+	}
+	pinchX := pinchedX[0] && pinchedX[1]
+	pinchY := pinchedY[0] && pinchedY[1]
+
+	var neg, pos types.Vector2
+	normals := make([]types.Vector2, 0, len(pushes))
+	for i, f := range pushes {
+		if (pinchX && math.Abs(float64(f.normal.X)) > axisEpsilon) || (pinchY && math.Abs(float64(f.normal.Y)) > axisEpsilon) {
+			f = alts[i]
+		}
+
+		// Keep the deepest push in each direction, smaller ones are covered by it
+		x, y := f.normal.X*f.depth, f.normal.Y*f.depth
+		neg.X, pos.X = min(neg.X, x), max(pos.X, x)
+		neg.Y, pos.Y = min(neg.Y, y), max(pos.Y, y)
+		normals = append(normals, f.normal)
+	}
+
+	sep.Correction = types.Vector2{X: neg.X + pos.X, Y: neg.Y + pos.Y}
+	sep.Normal = CombineNormals(normals...)
+	return sep, true
+}
+
+// CombineNormals merges the normals of several surfaces touched at once into a
+// single unit normal. Two bricks touched along a seam give one flat normal,
+// so the ball reflects once rather than twice, and an inside corner gives a
+// diagonal normal that sends the ball back out. Normals that cancel out give
+// the zero vector.
+func CombineNormals(normals ...types.Vector2) types.Vector2 {
+	var sum types.Vector2
+	for _, n := range normals {
+		sum.X += n.X
+		sum.Y += n.Y
+	}
+
+	length := float32(math.Sqrt(float64(dot(sum, sum))))
+	if length < axisEpsilon {
+		return types.Vector2{}
+	}
+	return types.Vector2{X: sum.X / length, Y: sum.Y / length}
+}
+
+// axisEpsilon is the smallest normal component treated as pointing along an
+// axis
+const axisEpsilon = 1e-4
+
+// rayCircle returns the first time in [0, 1] at which the ray p + t*d enters
+// the circle around center
+func rayCircle(p, d, center types.Vector2, radius float32) (float32, bool) {
+	f := types.Vector2{X: p.X - center.X, Y: p.Y - center.Y}
+	a := dot(d, d)
+	b := 2 * dot(f, d)
+	c := dot(f, f) - radius*radius
+	if a == 0 {
+		return 0, false
+	}
+
+	disc := b*b - 4*a*c
+	if disc < 0 {
+		return 0, false
+	}
+
+	t := (-b - float32(math.Sqrt(float64(disc)))) / (2 * a)
+	if t < 0 || t > 1 {
+		return 0, false
+	}
+	return t, true
+}
+
+// face is one side of a rectangle and how deep another shape is behind it
+type face struct {
+	normal types.Vector2
+	depth  float32
+}
+
+// penetration returns the face of b that a is least deep behind, and the
+// shallowest face on the other axis
+func penetration(a, b types.Rectangle) (shallowest, alt face) {
+	horizontal, vertical := axisPenetration(a, b)
+	if vertical.depth <= horizontal.depth {
+		return vertical, horizontal
+	}
+	return horizontal, vertical
+}
+
+// axisPenetration returns the shallowest face of b that a is behind along
+// each axis
+func axisPenetration(a, b types.Rectangle) (horizontal, vertical face) {
+	left := face{types.Vector2{X: -1}, a.X + a.Width - b.X}
+	right := face{types.Vector2{X: 1}, b.X + b.Width - a.X}
+	top := face{types.Vector2{Y: -1}, a.Y + a.Height - b.Y}
+	bottom := face{types.Vector2{Y: 1}, b.Y + b.Height - a.Y}
+
+	horizontal = left
+	if right.depth < left.depth {
+		horizontal = right
+	}
+	vertical = top
+	if bottom.depth < top.depth {
+		vertical = bottom
+	}
+	return horizontal, vertical
+}
+
+func dot(a, b types.Vector2) float32 {
+	return a.X*b.X + a.Y*b.Y
+}
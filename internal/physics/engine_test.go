@@ -0,0 +1,218 @@
+package physics
+
+import (
+	"breakout/internal/types"
+	"math"
+	"testing"
+)
+
+// circleIn returns the circle inscribed in a square box
+func circleIn(box types.Rectangle) types.Circle {
+	return types.Circle{
+		Center: types.Vector2{X: box.X + box.Width/2, Y: box.Y + box.Height/2},
+		Radius: box.Width / 2,
+	}
+}
+
+func TestSweep(t *testing.T) {
+	// A ball in a 10x10 box above a 50x10 brick, with 20 pixels of clearance
+	box := types.Rectangle{X: 20, Y: 0, Width: 10, Height: 10}
+	brick := types.Rectangle{X: 0, Y: 30, Width: 50, Height: 10}
+
+	tests := []struct {
+		name       string
+		box        types.Rectangle
+		delta      types.Vector2
+		wantHit    bool
+		wantTime   float32
+		wantNormal types.Vector2
+	}{
+		{"Falls onto top face", box, types.Vector2{Y: 40}, true, 0.5, types.Vector2{Y: -1}},
+		{"Stops short", box, types.Vector2{Y: 10}, false, 0, types.Vector2{}},
+		{"Moves away", box, types.Vector2{Y: -40}, false, 0, types.Vector2{}},
+		{"Tunnels through in one step", box, types.Vector2{Y: 200}, true, 0.1, types.Vector2{Y: -1}},
+		{"Passes beside", types.Rectangle{X: 60, Y: 0, Width: 10, Height: 10}, types.Vector2{Y: 40}, false, 0, types.Vector2{}},
+		{"Hits left face", types.Rectangle{X: -30, Y: 30, Width: 10, Height: 10}, types.Vector2{X: 40}, true, 0.5, types.Vector2{X: -1}},
+		{"Hits bottom face", types.Rectangle{X: 20, Y: 60, Width: 10, Height: 10}, types.Vector2{Y: -40}, true, 0.5, types.Vector2{Y: 1}},
+		{"Diagonal onto top face", types.Rectangle{X: 0, Y: 0, Width: 10, Height: 10}, types.Vector2{X: 20, Y: 40}, true, 0.5, types.Vector2{Y: -1}},
+		{"Starts overlapping and moving in", types.Rectangle{X: 20, Y: 22, Width: 10, Height: 10}, types.Vector2{Y: 5}, true, 0, types.Vector2{Y: -1}},
+		{"Starts overlapping and moving out", types.Rectangle{X: 20, Y: 22, Width: 10, Height: 10}, types.Vector2{Y: -5}, false, 0, types.Vector2{}},
+		// The box would clip the top-left corner, but the round ball passes it by
+		{"Misses corner", types.Rectangle{X: -19, Y: 31, Width: 10, Height: 10}, types.Vector2{X: 20, Y: -20}, false, 0, types.Vector2{}},
+		// Falling straight down with the centre 3 pixels left of the brick
+		{"Hits corner", types.Rectangle{X: -8, Y: 0, Width: 10, Height: 10}, types.Vector2{Y: 40}, true, 0.525, types.Vector2{X: -0.6, Y: -0.8}},
+	}
+
+	engine := New()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			contact, hit := engine.Sweep(circleIn(tt.box), tt.delta, brick)
+			if hit != tt.wantHit {
+				t.Fatalf("Sweep() hit = %v, want %v", hit, tt.wantHit)
+			}
+			if !hit {
+				return
+			}
+			if diff := contact.Time - tt.wantTime; diff > 1e-5 || diff < -1e-5 {
+				t.Errorf("Sweep() time = %v, want %v", contact.Time, tt.wantTime)
+			}
+			if !closeTo(contact.Normal, tt.wantNormal) {
+				t.Errorf("Sweep() normal = %v, want %v", contact.Normal, tt.wantNormal)
+			}
+		})
+	}
+}
+
+func TestCircleRect(t *testing.T) {
+	brick := types.Rectangle{X: 0, Y: 0, Width: 50, Height: 10}
+
+	tests := []struct {
+		name       string
+		center     types.Vector2
+		wantHit    bool
+		wantNormal types.Vector2
+		wantDepth  float32
+	}{
+		{"Below face", types.Vector2{X: 25, Y: 13}, true, types.Vector2{Y: 1}, 2},
+		{"Left face", types.Vector2{X: -3, Y: 5}, true, types.Vector2{X: -1}, 2},
+		{"Bottom-right corner", types.Vector2{X: 52.4, Y: 13.2}, true, types.Vector2{X: 0.6, Y: 0.8}, 1},
+		{"Outside corner reach", types.Vector2{X: 54, Y: 14}, false, types.Vector2{}, 0},
+		{"Centre inside", types.Vector2{X: 25, Y: 8}, true, types.Vector2{Y: 1}, 7},
+	}
+
+	engine := New()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, hit := engine.CircleRect(types.Circle{Center: tt.center, Radius: 5}, brick)
+			if hit != tt.wantHit {
+				t.Fatalf("CircleRect() hit = %v, want %v", hit, tt.wantHit)
+			}
+			if !hit {
+				return
+			}
+			if !closeTo(p.Normal, tt.wantNormal) {
+				t.Errorf("CircleRect() normal = %v, want %v", p.Normal, tt.wantNormal)
+			}
+			if diff := p.Depth - tt.wantDepth; diff > 1e-4 || diff < -1e-4 {
+				t.Errorf("CircleRect() depth = %v, want %v", p.Depth, tt.wantDepth)
+			}
+		})
+	}
+}
+
+func TestSeparate(t *testing.T) {
+	tests := []struct {
+		name           string
+		box            types.Rectangle
+		others         []types.Rectangle
+		wantHits       int
+		wantCorrection types.Vector2
+		wantNormal     types.Vector2
+	}{
+		{
+			name:           "Single brick from below",
+			box:            types.Rectangle{X: 20, Y: 8, Width: 10, Height: 10},
+			others:         []types.Rectangle{{X: 0, Y: 0, Width: 50, Height: 10}},
+			wantHits:       1,
+			wantCorrection: types.Vector2{Y: 2},
+			wantNormal:     types.Vector2{Y: 1},
+		},
+		{
+			// Two bricks side by side, the box straddles the gap between them
+			name: "Seam",
+			box:  types.Rectangle{X: 45, Y: 7, Width: 10, Height: 10},
+			others: []types.Rectangle{
+				{X: 0, Y: 0, Width: 48, Height: 10},
+				{X: 52, Y: 0, Width: 48, Height: 10},
+			},
+			wantHits:       2,
+			wantCorrection: types.Vector2{Y: 3},
+			wantNormal:     types.Vector2{Y: 1},
+		},
+		{
+			// One brick above and one to the right form an inside corner
+			name: "Inside corner",
+			box:  types.Rectangle{X: 38, Y: 8, Width: 10, Height: 10},
+			others: []types.Rectangle{
+				{X: 0, Y: 0, Width: 50, Height: 10},
+				{X: 47, Y: 10, Width: 50, Height: 20},
+			},
+			wantHits:       2,
+			wantCorrection: types.Vector2{X: -1, Y: 2},
+			wantNormal:     types.Vector2{X: -math.Sqrt2 / 2, Y: math.Sqrt2 / 2},
+		},
+		{
+			// Two bricks squeeze the box from the left and right
+			name: "Pinch",
+			box:  types.Rectangle{X: 47, Y: 6, Width: 10, Height: 10},
+			others: []types.Rectangle{
+				{X: 0, Y: 0, Width: 49, Height: 10},
+				{X: 55, Y: 0, Width: 50, Height: 10},
+			},
+			wantHits:       2,
+			wantCorrection: types.Vector2{Y: 4},
+			wantNormal:     types.Vector2{Y: 1},
+		},
+		{
+			name:     "No overlap",
+			box:      types.Rectangle{X: 20, Y: 20, Width: 10, Height: 10},
+			others:   []types.Rectangle{{X: 0, Y: 0, Width: 50, Height: 10}},
+			wantHits: 0,
+		},
+	}
+
+	engine := New()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sep, ok := engine.Separate(circleIn(tt.box), tt.others)
+			if ok != (tt.wantHits > 0) || len(sep.Hits) != tt.wantHits {
+				t.Fatalf("Separate() hit %d shapes, want %d", len(sep.Hits), tt.wantHits)
+			}
+			if !ok {
+				return
+			}
+			if !closeTo(sep.Correction, tt.wantCorrection) {
+				t.Errorf("Separate() correction = %v, want %v", sep.Correction, tt.wantCorrection)
+			}
+			if !closeTo(sep.Normal, tt.wantNormal) {
+				t.Errorf("Separate() normal = %v, want %v", sep.Normal, tt.wantNormal)
+			}
+
+			// The corrected ball must be clear of everything it overlapped
+			moved := circleIn(tt.box)
+			moved.Center.X += sep.Correction.X
+			moved.Center.Y += sep.Correction.Y
+			for _, other := range tt.others {
+				if p, hit := engine.CircleRect(moved, other); hit && p.Depth > 1e-4 {
+					t.Errorf("Corrected ball %v still overlaps %v", moved, other)
+				}
+			}
+		})
+	}
+}
+
+func TestCombineNormals(t *testing.T) {
+	tests := []struct {
+		name    string
+		normals []types.Vector2
+		want    types.Vector2
+	}{
+		{"Single face", []types.Vector2{{Y: 1}}, types.Vector2{Y: 1}},
+		{"Seam", []types.Vector2{{Y: 1}, {Y: 1}}, types.Vector2{Y: 1}},
+		{"Inside corner", []types.Vector2{{Y: 1}, {X: -1}}, types.Vector2{X: -math.Sqrt2 / 2, Y: math.Sqrt2 / 2}},
+		{"Opposing faces cancel", []types.Vector2{{X: 1}, {X: -1}}, types.Vector2{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := CombineNormals(tt.normals...); !closeTo(got, tt.want) {
+				t.Errorf("CombineNormals() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func closeTo(a, b types.Vector2) bool {
+	dx, dy := a.X-b.X, a.Y-b.Y
+	return dx < 1e-4 && dx > -1e-4 && dy < 1e-4 && dy > -1e-4
+}
@@ -0,0 +1,140 @@
+package physics
+
+import (
+	"breakout/internal/types"
+	"math"
+	"slices"
+)
+
+// DefaultCellSize is the grid cell size used by New, in pixels. It is a few
+// bricks wide, so a ball-sized query only visits one to four cells.
+const DefaultCellSize = 64
+
+// Grid is a uniform-grid broadphase over static rectangles. Queries only visit
+// the cells an area covers, and insertion and removal only touch the cells of
+// the shape involved, so the cost does not grow with the number of shapes.
+type Grid struct {
+	cellSize float32
+	cells    map[cell][]int
+	items    map[int]*gridItem
+	stamp    uint32
+}
+
+type cell struct {
+	x, y int32
+}
+
+type gridItem struct {
+	bounds types.Rectangle
+	min    cell
+	max    cell
+	stamp  uint32
+}
+
+// NewGrid creates an empty grid with the given cell size
+func NewGrid(cellSize float32) *Grid {
+	return &Grid{
+		cellSize: cellSize,
+		cells:    make(map[cell][]int),
+		items:    make(map[int]*gridItem),
+	}
+}
+
+// Len returns the number of shapes in the grid
+func (g *Grid) Len() int {
+	return len(g.items)
+}
+
+// Insert adds a shape under the given id, replacing any shape with that id
+func (g *Grid) Insert(id int, bounds types.Rectangle) {
+	g.Remove(id)
+
+	lo, hi := g.cellRange(bounds)
+	g.items[id] = &gridItem{bounds: bounds, min: lo, max: hi}
+	for x := lo.x; x <= hi.x; x++ {
+		for y := lo.y; y <= hi.y; y++ {
+			c := cell{x, y}
+			g.cells[c] = append(g.cells[c], id)
+		}
+	}
+}
+
+// Remove deletes the shape with the given id, if present
+func (g *Grid) Remove(id int) {
+	item, ok := g.items[id]
+	if !ok {
+		return
+	}
+	delete(g.items, id)
+
+	for x := item.min.x; x <= item.max.x; x++ {
+		for y := item.min.y; y <= item.max.y; y++ {
+			c := cell{x, y}
+			ids := g.cells[c]
+			if i := slices.Index(ids, id); i >= 0 {
+				ids[i] = ids[len(ids)-1]
+				ids = ids[:len(ids)-1]
+			}
+			if len(ids) == 0 {
+				delete(g.cells, c)
+			} else {
+				g.cells[c] = ids
+			}
+		}
+	}
+}
+
+// Clear removes every shape
+func (g *Grid) Clear() {
+	clear(g.cells)
+	clear(g.items)
+}
+
+// Bounds returns the rectangle stored under id
+func (g *Grid) Bounds(id int) (types.Rectangle, bool) {
+	item, ok := g.items[id]
+	if !ok {
+		return types.Rectangle{}, false
+	}
+	return item.bounds, true
+}
+
+// Query returns the ids of every shape whose bounds touch area, in ascending
+// order so callers visit them in the same order on every run
+func (g *Grid) Query(area types.Rectangle) []int {
+	g.stamp++
+
+	var ids []int
+	lo, hi := g.cellRange(area)
+	for x := lo.x; x <= hi.x; x++ {
+		for y := lo.y; y <= hi.y; y++ {
+			for _, id := range g.cells[cell{x, y}] {
+				item := g.items[id]
+				if item.stamp == g.stamp || !touches(item.bounds, area) {
+					continue
+				}
+				item.stamp = g.stamp
+				ids = append(ids, id)
+			}
+		}
+	}
+
+	slices.Sort(ids)
+	return ids
+}
+
+func (g *Grid) cellRange(r types.Rectangle) (lo, hi cell) {
+	lo = cell{g.cellCoord(r.X), g.cellCoord(r.Y)}
+	hi = cell{g.cellCoord(r.X + r.Width), g.cellCoord(r.Y + r.Height)}
+	return lo, hi
+}
+
+func (g *Grid) cellCoord(v float32) int32 {
+	return int32(math.Floor(float64(v / g.cellSize)))
+}
+
+// touches reports whether two rectangles overlap or share an edge
+func touches(a, b types.Rectangle) bool {
+	return a.X <= b.X+b.Width && a.X+a.Width >= b.X &&
+		a.Y <= b.Y+b.Height && a.Y+a.Height >= b.Y
+}
@@ -0,0 +1,160 @@
+package physics
+
+import (
+	"breakout/internal/types"
+	"fmt"
+	"math/rand"
+	"slices"
+	"testing"
+)
+
+// overlaps is the check a linear scan makes against every brick, for the
+// benchmark to compare the grid with
+func overlaps(a, b types.Rectangle) bool {
+	return a.X < b.X+b.Width && a.X+a.Width > b.X &&
+		a.Y < b.Y+b.Height && a.Y+a.Height > b.Y
+}
+
+// brickField lays out count bricks in rows the way the game does
+func brickField(count int) []types.Rectangle {
+	const (
+		perRow  = 100
+		width   = 40
+		height  = 10
+		spacing = 5
+	)
+
+	bricks := make([]types.Rectangle, count)
+	for i := range bricks {
+		col, row := i%perRow, i/perRow
+		bricks[i] = types.Rectangle{
+			X:      float32(col*(width+spacing) + spacing),
+			Y:      float32(row*(height+spacing) + spacing),
+			Width:  width,
+			Height: height,
+		}
+	}
+	return bricks
+}
+
+func linearQuery(bricks []types.Rectangle, removed map[int]bool, area types.Rectangle) []int {
+	var ids []int
+	for i, b := range bricks {
+		if !removed[i] && touches(b, area) {
+			ids = append(ids, i)
+		}
+	}
+	return ids
+}
+
+func TestGridQueryMatchesLinearScan(t *testing.T) {
+	bricks := brickField(2000)
+	grid := NewGrid(DefaultCellSize)
+	for i, b := range bricks {
+		grid.Insert(i, b)
+	}
+
+	rng := rand.New(rand.NewSource(1))
+	removed := make(map[int]bool)
+
+	for step := 0; step < 500; step++ {
+		// Remove a brick every other step to exercise incremental removal
+		if step%2 == 0 {
+			id := rng.Intn(len(bricks))
+			grid.Remove(id)
+			removed[id] = true
+		}
+
+		area := types.Rectangle{
+			X:      rng.Float32()*4600 - 50,
+			Y:      rng.Float32()*320 - 10,
+			Width:  rng.Float32() * 120,
+			Height: rng.Float32() * 60,
+		}
+
+		got := grid.Query(area)
+		want := linearQuery(bricks, removed, area)
+		if !slices.Equal(got, want) {
+			t.Fatalf("Step %d: Query(%v) = %v, want %v", step, area, got, want)
+		}
+	}
+
+	if want := len(bricks) - len(removed); grid.Len() != want {
+		t.Errorf("Len() = %d, want %d", grid.Len(), want)
+	}
+}
+
+func TestGridInsertReplacesExistingID(t *testing.T) {
+	grid := NewGrid(DefaultCellSize)
+	grid.Insert(1, types.Rectangle{X: 0, Y: 0, Width: 10, Height: 10})
+	grid.Insert(1, types.Rectangle{X: 500, Y: 500, Width: 10, Height: 10})
+
+	if ids := grid.Query(types.Rectangle{X: 0, Y: 0, Width: 10, Height: 10}); len(ids) != 0 {
+		t.Errorf("Old bounds still found after re-insert: %v", ids)
+	}
+	if ids := grid.Query(types.Rectangle{X: 500, Y: 500, Width: 10, Height: 10}); !slices.Equal(ids, []int{1}) {
+		t.Errorf("Query() at new bounds = %v, want [1]", ids)
+	}
+}
+
+func BenchmarkBroadphase(b *testing.B) {
+	// A ball-sized query swept over one tick, in the middle of the field
+	area := SweptArea(types.Rectangle{X: 1000, Y: 100, Width: 10, Height: 10}, types.Vector2{X: 3, Y: -3})
+
+	for _, count := range []int{112, 1000, 10000} {
+		bricks := brickField(count)
+
+		b.Run(fmt.Sprintf("LinearScan/%d", count), func(b *testing.B) {
+			for b.Loop() {
+				hits := 0
+				for _, brick := range bricks {
+					if overlaps(area, brick) {
+						hits++
+					}
+				}
+				_ = hits
+			}
+		})
+
+		b.Run(fmt.Sprintf("Grid/%d", count), func(b *testing.B) {
+			grid := NewGrid(DefaultCellSize)
+			for i, brick := range bricks {
+				grid.Insert(i, brick)
+			}
+			for b.Loop() {
+				_ = grid.Query(area)
+			}
+		})
+	}
+}
+
+func BenchmarkRemoval(b *testing.B) {
+	for _, count := range []int{1000, 10000} {
+		bricks := brickField(count)
+
+		b.Run(fmt.Sprintf("SliceAppend/%d", count), func(b *testing.B) {
+			for b.Loop() {
+				b.StopTimer()
+				live := slices.Clone(bricks)
+				b.StartTimer()
+				for len(live) > count/2 {
+					live = append(live[:0], live[1:]...)
+				}
+			}
+		})
+
+		b.Run(fmt.Sprintf("Grid/%d", count), func(b *testing.B) {
+			for b.Loop() {
+				b.StopTimer()
+				grid := NewGrid(DefaultCellSize)
+				for i, brick := range bricks {
+					grid.Insert(i, brick)
+				}
+				b.StartTimer()
+				for i := 0; i < count/2; i++ {
+					grid.Remove(i)
+				}
+			}
+		})
+	}
+}
@@ -1,22 +1,28 @@
 package renderer
 
 import (
+	"breakout/internal/config"
+	"breakout/internal/scores"
+	"fmt"
+	"image/color"
 	"strconv"
 
 	rl "github.com/gen2brain/raylib-go/raylib"
 )
 
-const (
-	WindowWidth  = 768
-	WindowHeight = 1024
-)
-
-// Renderer handles all rendering operations
-type Renderer struct{}
+// Renderer handles all rendering operations. It draws onto the playfield,
+// which a Screen scales to the window.
+type Renderer struct {
+	width  int32
+	height int32
+}
 
-// New creates a new renderer
+// New creates a new renderer drawing in playfield coordinates
 func New() *Renderer {
-	return &Renderer{}
+	return &Renderer{
+		width:  config.PlayfieldWidth,
+		height: config.PlayfieldHeight,
+	}
 }
 
 // DrawScore renders the current score
@@ -24,25 +30,114 @@ func (r *Renderer) DrawScore(score int32) {
 	rl.DrawText(strconv.Itoa(int(score)), 20, 20, 40, rl.RayWhite)
 }
 
-// DrawGameWon renders the game won screen
-func (r *Renderer) DrawGameWon(score int32) {
-	r.drawCenteredText("Game Won! Press R to Restart", WindowHeight/2, 20)
-	r.drawCenteredText("Final Score: "+strconv.Itoa(int(score)), WindowHeight/2+40, 20)
+// DrawStatus renders the balls left and the difficulty being played
+func (r *Renderer) DrawStatus(serves int32, difficulty string) {
+	text := fmt.Sprintf("Balls: %d  %s", serves, difficulty)
+	rl.DrawText(text, r.width-rl.MeasureText(text, 20)-20, 30, 20, rl.LightGray)
+}
+
+// DrawLevel renders the level's number and name, and its par score if it has
+// one
+func (r *Renderer) DrawLevel(number int32, name string, par int32) {
+	text := fmt.Sprintf("Level %d: %s", number, name)
+	if par > 0 {
+		text += fmt.Sprintf("  Par %d", par)
+	}
+	r.drawCenteredText(text, 70, 20)
+}
+
+// DrawBackground fills the playfield with a level's background colour
+func (r *Renderer) DrawBackground(c color.RGBA) {
+	rl.DrawRectangle(0, 0, r.width, r.height, c)
+}
+
+// DrawGameWon renders the game won screen, naming the key that restarts
+func (r *Renderer) DrawGameWon(score int32, restartKey string) {
+	r.drawCenteredText("Game Won! Press "+restartKey+" to Restart", r.height/2, 20)
+	r.drawCenteredText("Final Score: "+strconv.Itoa(int(score)), r.height/2+40, 20)
+}
+
+// DrawGameLost renders the game lost screen, naming the key that restarts
+func (r *Renderer) DrawGameLost(score int32, restartKey string) {
+	r.drawCenteredText("Game Lost! Press "+restartKey+" to Restart", r.height/2, 20)
+	r.drawCenteredText("Final Score: "+strconv.Itoa(int(score)), r.height/2+40, 20)
+}
+
+// DrawHighScores renders the best scores on a difficulty below the game over
+// text. Games on custom settings are not ranked.
+func (r *Renderer) DrawHighScores(difficulty string, entries []scores.Entry) {
+	y := r.height/2 + 100
+	if difficulty == config.CustomDifficulty {
+		r.drawCenteredText("Custom settings, score not recorded", y, 20)
+		return
+	}
+
+	r.drawCenteredText("High Scores ("+difficulty+")", y, 20)
+	for i, e := range entries {
+		y += 25
+		r.drawCenteredText(fmt.Sprintf("%2d. %5d  %s", i+1, e.Score, e.Time.Format("2006-01-02")), y, 20)
+	}
+}
+
+// Menu layout, in playfield pixels
+const (
+	menuItemHeight = 30
+	menuItemSize   = 20
+)
+
+// DrawMenu renders a titled list of choices with the selected one marked and
+// its description below the list
+func (r *Renderer) DrawMenu(title string, items []string, selected int, description string) {
+	r.drawCenteredText(title, r.height/3, 30)
+
+	y := r.menuTop()
+	for i, item := range items {
+		if i == selected {
+			item = "> " + item + " <"
+		}
+		r.drawCenteredText(item, y+(menuItemHeight-menuItemSize)/2, menuItemSize)
+		y += menuItemHeight
+	}
+
+	r.drawCenteredText(description, y+20, 16)
+}
+
+// MenuItemAt returns the index of the menu item drawn across playfield row y,
+// for a menu of n items
+func (r *Renderer) MenuItemAt(n int, y float32) (int, bool) {
+	row := (y - float32(r.menuTop())) / menuItemHeight
+	if row < 0 || row >= float32(n) {
+		return 0, false
+	}
+	return int(row), true
+}
+
+func (r *Renderer) menuTop() int32 {
+	return r.height/3 + 55
+}
+
+// DrawPaused renders the paused screen overlay, naming the key that resumes
+func (r *Renderer) DrawPaused(serveKey string) {
+	r.drawCenteredText("Paused! Press "+serveKey+" to Resume", r.height/2+40, 20)
 }
 
-// DrawGameLost renders the game lost screen
-func (r *Renderer) DrawGameLost(score int32) {
-	r.drawCenteredText("Game Lost! Press R to Restart", WindowHeight/2, 20)
-	r.drawCenteredText("Final Score: "+strconv.Itoa(int(score)), WindowHeight/2+40, 20)
+// DrawNotice renders a short status message at the bottom of the screen
+func (r *Renderer) DrawNotice(text string) {
+	rl.DrawText(text, 20, r.height-30, 16, rl.LightGray)
 }
 
-// DrawPaused renders the paused screen overlay
-func (r *Renderer) DrawPaused() {
-	r.drawCenteredText("Paused! Press Space to Resume", WindowHeight/2+40, 20)
+// DrawError renders an error at the bottom of the screen, one line per entry
+func (r *Renderer) DrawError(lines []string) {
+	const lineHeight = 20
+	y := r.height - 10 - lineHeight*int32(len(lines))
+	for _, line := range lines {
+		rl.DrawText(line, 20, y, 16, rl.Red)
+		y += lineHeight
+	}
 }
 
 func (r *Renderer) drawCenteredText(text string, y int32, fontSize int32) {
 	textWidth := rl.MeasureText(text, fontSize)
-	x := (WindowWidth - textWidth) / 2
+	x := (r.width - textWidth) / 2
 	rl.DrawText(text, x, y, fontSize, rl.RayWhite)
 }
\ No newline at end of file
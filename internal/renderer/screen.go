@@ -0,0 +1,59 @@
+package renderer
+
+import (
+	"breakout/internal/config"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
+
+// Screen draws each frame onto an offscreen playfield-sized canvas, then
+// scales it into the window with letterboxing. Between BeginFrame and
+// EndFrame, everything is drawn in playfield coordinates and the raylib mouse
+// position is reported in playfield coordinates too.
+type Screen struct {
+	canvas rl.RenderTexture2D
+	view   Viewport
+}
+
+// NewScreen creates the canvas. The window must already be open.
+func NewScreen() *Screen {
+	canvas := rl.LoadRenderTexture(config.PlayfieldWidth, config.PlayfieldHeight)
+	rl.SetTextureFilter(canvas.Texture, rl.FilterBilinear)
+	return &Screen{canvas: canvas, view: Viewport{Scale: 1}}
+}
+
+// Unload releases the canvas
+func (s *Screen) Unload() {
+	rl.UnloadRenderTexture(s.canvas)
+}
+
+// BeginFrame fits the playfield to the window's current size and starts
+// drawing onto the canvas
+func (s *Screen) BeginFrame() {
+	s.view = Fit(float32(rl.GetScreenWidth()), float32(rl.GetScreenHeight()), config.PlayfieldWidth, config.PlayfieldHeight)
+
+	offsetX, offsetY, scale := s.view.MouseTransform()
+	rl.SetMouseOffset(offsetX, offsetY)
+	rl.SetMouseScale(scale, scale)
+
+	rl.BeginTextureMode(s.canvas)
+	rl.ClearBackground(rl.Black)
+}
+
+// EndFrame finishes the canvas and presents it in the window
+func (s *Screen) EndFrame() {
+	rl.EndTextureMode()
+
+	rl.BeginDrawing()
+	rl.ClearBackground(rl.Black)
+	// Render textures are stored upside down, so flip the source
+	source := rl.Rectangle{Width: config.PlayfieldWidth, Height: -config.PlayfieldHeight}
+	dest := rl.Rectangle{
+		X:      s.view.X,
+		Y:      s.view.Y,
+		Width:  config.PlayfieldWidth * s.view.Scale,
+		Height: config.PlayfieldHeight * s.view.Scale,
+	}
+	rl.DrawTexturePro(s.canvas.Texture, source, dest, rl.Vector2{}, 0, rl.White)
+	rl.EndDrawing()
+}
@@ -0,0 +1,36 @@
+package renderer
+
+import "math"
+
+// Viewport is where the playfield appears in the window: scaled uniformly to
+// the largest size that fits, and centred with black bars filling the rest
+type Viewport struct {
+	// X and Y are the window position of the playfield's top left corner,
+	// in whole pixels
+	X, Y float32
+	// Scale is the size of a playfield pixel in window pixels
+	Scale float32
+}
+
+// Fit returns the viewport showing a playfield of the given size in a window
+// of the given size
+func Fit(windowWidth, windowHeight, fieldWidth, fieldHeight float32) Viewport {
+	scale := min(windowWidth/fieldWidth, windowHeight/fieldHeight)
+	if scale <= 0 {
+		// A minimized window has no size, keep the maths finite
+		return Viewport{Scale: 1}
+	}
+	return Viewport{
+		X:     float32(math.Floor(float64(windowWidth-fieldWidth*scale) / 2)),
+		Y:     float32(math.Floor(float64(windowHeight-fieldHeight*scale) / 2)),
+		Scale: scale,
+	}
+}
+
+// MouseTransform returns the offset and scale to give raylib's SetMouseOffset
+// and SetMouseScale so the mouse is reported in playfield coordinates. Raylib
+// reports it at (position + offset) * scale, so the bars map outside the
+// playfield.
+func (v Viewport) MouseTransform() (offsetX, offsetY int, scale float32) {
+	return -int(v.X), -int(v.Y), 1 / v.Scale
+}
@@ -0,0 +1,57 @@
+package renderer
+
+import (
+	"breakout/internal/types"
+	"testing"
+)
+
+func TestFit(t *testing.T) {
+	tests := []struct {
+		name          string
+		width, height float32
+		want          Viewport
+	}{
+		{"Same size", 768, 1024, Viewport{X: 0, Y: 0, Scale: 1}},
+		{"Wide window bars the sides", 1920, 1080, Viewport{X: 555, Y: 0, Scale: 1080.0 / 1024}},
+		{"Tall window bars the top and bottom", 384, 1024, Viewport{X: 0, Y: 256, Scale: 0.5}},
+		{"Double size", 1536, 2048, Viewport{X: 0, Y: 0, Scale: 2}},
+		{"Minimized", 0, 0, Viewport{Scale: 1}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Fit(tt.width, tt.height, 768, 1024); got != tt.want {
+				t.Errorf("Fit(%v, %v) = %+v, want %+v", tt.width, tt.height, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMouseTransformMapsToPlayfield(t *testing.T) {
+	v := Fit(1920, 1080, 768, 1024)
+	offsetX, offsetY, scale := v.MouseTransform()
+	// As raylib reports a mouse at the given window position
+	mouse := func(x, y float32) types.Vector2 {
+		return types.Vector2{X: (x + float32(offsetX)) * scale, Y: (y + float32(offsetY)) * scale}
+	}
+
+	corners := []types.Vector2{{X: 0, Y: 0}, {X: 768, Y: 1024}, {X: 384, Y: 512}}
+	for _, p := range corners {
+		got := mouse(v.X+p.X*v.Scale, v.Y+p.Y*v.Scale)
+		if d := max(abs(got.X-p.X), abs(got.Y-p.Y)); d > 1e-3 {
+			t.Errorf("mouse over playfield point %v = %v", p, got)
+		}
+	}
+
+	// The left bar is outside the playfield
+	if got := mouse(100, 500); got.X >= 0 {
+		t.Errorf("mouse on the bar = %v, want negative X", got)
+	}
+}
+
+func abs(x float32) float32 {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
@@ -0,0 +1,261 @@
+// Package replay records the input of a game session and plays it back.
+//
+// The game runs at a fixed tick rate and reads all of its input through an
+// input.Source once per tick, so a session is fully described by its config,
+// the levels it was played on, the seed of any random input and the keys held
+// and pressed on every tick.
+package replay
+
+import (
+	"breakout/internal/config"
+	"breakout/internal/levels"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+)
+
+const (
+	// Version is the replay file format version written by this build.
+	// Version 2 added the levels.
+	Version = 2
+
+	magic = "BKRP"
+	// maxKeys is how many keys fit in a run's bitmasks
+	maxKeys = 64
+)
+
+// ErrNotReplay is returned when reading data that is not a replay file
+var ErrNotReplay = errors.New("not a replay file")
+
+// Replay is a recorded session, holding everything needed to play it again
+// exactly
+type Replay struct {
+	// Seed is the seed of the random input source the session was played
+	// with, 0 when played from the keyboard
+	Seed int64
+	// Config is the configuration the game was created with
+	Config config.Config
+	// Levels are the levels the game was played through, stored whole as
+	// the level files can change or go missing
+	Levels []*levels.Level
+	// Keys lists the recorded keys. Key i is bit i of a run's masks.
+	Keys []int32
+	// Runs holds the input of every tick, run-length encoded
+	Runs []Run
+}
+
+// Run is a stretch of consecutive ticks with the same input
+type Run struct {
+	Down    uint64
+	Pressed uint64
+	Ticks   uint64
+}
+
+// Ticks returns the number of recorded ticks
+func (r *Replay) Ticks() uint64 {
+	var n uint64
+	for _, run := range r.Runs {
+		n += run.Ticks
+	}
+	return n
+}
+
+// Load reads a replay file
+func Load(path string) (*Replay, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	r := &Replay{}
+	if err := r.UnmarshalBinary(data); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return r, nil
+}
+
+// Save writes the replay to a file
+func (r *Replay) Save(path string) error {
+	data, err := r.MarshalBinary()
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0o644)
+}
+
+// MarshalBinary encodes the replay. The format is the magic "BKRP" followed by
+// unsigned varints for the version, the config's JSON length, the level count,
+// each level file's length, the key count and the run count, and signed
+// varints for the seed and each key:
+//
+//	magic version seed len(config) config len(levels) (len(level) level)... len(keys) keys... len(runs) (down pressed ticks)...
+func (r *Replay) MarshalBinary() ([]byte, error) {
+	if len(r.Keys) > maxKeys {
+		return nil, fmt.Errorf("replay records %d keys, at most %d fit", len(r.Keys), maxKeys)
+	}
+
+	cfg, err := config.Marshal(r.Config)
+	if err != nil {
+		return nil, err
+	}
+
+	buf := []byte(magic)
+	buf = binary.AppendUvarint(buf, Version)
+	buf = binary.AppendVarint(buf, r.Seed)
+	buf = binary.AppendUvarint(buf, uint64(len(cfg)))
+	buf = append(buf, cfg...)
+
+	buf = binary.AppendUvarint(buf, uint64(len(r.Levels)))
+	for _, l := range r.Levels {
+		data := l.Format()
+		buf = binary.AppendUvarint(buf, uint64(len(data)))
+		buf = append(buf, data...)
+	}
+
+	buf = binary.AppendUvarint(buf, uint64(len(r.Keys)))
+	for _, key := range r.Keys {
+		buf = binary.AppendVarint(buf, int64(key))
+	}
+
+	buf = binary.AppendUvarint(buf, uint64(len(r.Runs)))
+	for _, run := range r.Runs {
+		buf = binary.AppendUvarint(buf, run.Down)
+		buf = binary.AppendUvarint(buf, run.Pressed)
+		buf = binary.AppendUvarint(buf, run.Ticks)
+	}
+	return buf, nil
+}
+
+// UnmarshalBinary decodes a replay written by MarshalBinary
+func (r *Replay) UnmarshalBinary(data []byte) error {
+	if !bytes.HasPrefix(data, []byte(magic)) {
+		return ErrNotReplay
+	}
+	d := decoder{rd: bytes.NewReader(data[len(magic):])}
+
+	switch version := d.uvarint(); {
+	case d.err == nil && version < Version:
+		// Playing the levels of this build instead would silently desync
+		return fmt.Errorf("replay version %d does not record its levels, this build reads version %d", version, Version)
+	case d.err == nil && version > Version:
+		return fmt.Errorf("unsupported replay version %d, this build reads version %d", version, Version)
+	}
+	seed := d.varint()
+
+	var cfg config.Config
+	if raw := d.bytes(d.uvarint()); d.err == nil {
+		// Configs of an older version are upgraded like config files
+		var err error
+		if cfg, err = config.Parse(raw); err != nil {
+			return fmt.Errorf("reading replay config: %w", err)
+		}
+	}
+
+	levelCount := d.count()
+	played := make([]*levels.Level, 0, levelCount)
+	for i := range levelCount {
+		raw := d.bytes(d.uvarint())
+		if d.err != nil {
+			break
+		}
+		// A level this build reads differently, such as one using a brick
+		// type it lacks, could not be played back the same
+		l, err := levels.Parse(raw)
+		if err != nil {
+			return fmt.Errorf("reading replay level %d: %w", i+1, err)
+		}
+		played = append(played, l)
+	}
+
+	keyCount := d.count()
+	if keyCount > maxKeys {
+		return fmt.Errorf("replay records %d keys, at most %d fit", keyCount, maxKeys)
+	}
+	keys := make([]int32, 0, keyCount)
+	for range keyCount {
+		keys = append(keys, int32(d.varint()))
+	}
+
+	runCount := d.count()
+	runs := make([]Run, 0, runCount)
+	for range runCount {
+		runs = append(runs, Run{Down: d.uvarint(), Pressed: d.uvarint(), Ticks: d.uvarint()})
+	}
+
+	if d.err != nil {
+		return fmt.Errorf("reading replay: %w", d.err)
+	}
+	if d.rd.Len() != 0 {
+		return fmt.Errorf("reading replay: %d unexpected trailing bytes", d.rd.Len())
+	}
+
+	if len(played) == 0 {
+		return errors.New("reading replay: no levels")
+	}
+
+	*r = Replay{Seed: seed, Config: cfg, Levels: played, Keys: keys, Runs: runs}
+	return nil
+}
+
+// decoder reads varints, remembering the first error so the caller can check
+// once at the end
+type decoder struct {
+	rd  *bytes.Reader
+	err error
+}
+
+func (d *decoder) uvarint() uint64 {
+	if d.err != nil {
+		return 0
+	}
+	v, err := binary.ReadUvarint(d.rd)
+	d.fail(err)
+	return v
+}
+
+func (d *decoder) varint() int64 {
+	if d.err != nil {
+		return 0
+	}
+	v, err := binary.ReadVarint(d.rd)
+	d.fail(err)
+	return v
+}
+
+// count reads a length, rejecting any longer than the remaining data could
+// hold so a corrupt file cannot cause a huge allocation
+func (d *decoder) count() int {
+	n := d.uvarint()
+	if d.err == nil && n > uint64(d.rd.Len()) {
+		d.fail(io.ErrUnexpectedEOF)
+	}
+	if d.err != nil {
+		return 0
+	}
+	return int(n)
+}
+
+func (d *decoder) bytes(n uint64) []byte {
+	if d.err == nil && n > uint64(d.rd.Len()) {
+		d.fail(io.ErrUnexpectedEOF)
+	}
+	if d.err != nil {
+		return nil
+	}
+	b := make([]byte, n)
+	_, err := io.ReadFull(d.rd, b)
+	d.fail(err)
+	return b
+}
+
+func (d *decoder) fail(err error) {
+	if err == io.EOF {
+		err = io.ErrUnexpectedEOF
+	}
+	if d.err == nil {
+		d.err = err
+	}
+}
@@ -0,0 +1,207 @@
+package replay
+
+import (
+	"breakout/internal/config"
+	"breakout/internal/game"
+	"breakout/internal/input"
+	"breakout/internal/levels"
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"io"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func record(t *testing.T, seed int64, ticks int) (*Replay, []byte) {
+	t.Helper()
+
+	cfg := config.Default()
+	recorder := NewRecorder(input.NewRandom(seed), seed, cfg, levels.Builtin())
+	g, err := game.NewHeadless(cfg, recorder)
+	if err != nil {
+		t.Fatalf("NewHeadless() error = %v", err)
+	}
+	g.Initialize()
+	for i := 0; i < ticks; i++ {
+		g.Tick()
+	}
+
+	state, err := json.Marshal(g.State())
+	if err != nil {
+		t.Fatalf("marshal state: %v", err)
+	}
+	return recorder.Replay(), state
+}
+
+func TestReplayRoundTrip(t *testing.T) {
+	want, _ := record(t, 3, 2000)
+	want.Config.Game.PaddleEnglish = 0.5
+
+	path := filepath.Join(t.TempDir(), "session.bkr")
+	if err := want.Save(path); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	got, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Load() = %+v, want %+v", got, want)
+	}
+	if got.Ticks() != 2000 {
+		t.Errorf("Ticks() = %d, want 2000", got.Ticks())
+	}
+}
+
+func TestRecorderMergesRepeatedInput(t *testing.T) {
+	r, _ := record(t, 1, 5000)
+
+	if r.Ticks() != 5000 {
+		t.Fatalf("Ticks() = %d, want 5000", r.Ticks())
+	}
+	// The random player holds each key for at least 10 ticks
+	if len(r.Runs) > 5000/10 {
+		t.Errorf("len(Runs) = %d, want at most %d", len(r.Runs), 5000/10)
+	}
+	for i := 1; i < len(r.Runs); i++ {
+		prev, run := r.Runs[i-1], r.Runs[i]
+		if prev.Down == run.Down && prev.Pressed == run.Pressed {
+			t.Fatalf("runs %d and %d hold the same input and were not merged", i-1, i)
+		}
+	}
+}
+
+func TestPlayerReproducesSource(t *testing.T) {
+	r, _ := record(t, 7, 3000)
+
+	source := input.NewRandom(7)
+	player := NewPlayer(r)
+	for tick := 0; tick < 3000; tick++ {
+		if player.Done() {
+			t.Fatalf("Done() = true after %d of 3000 ticks", tick)
+		}
+		source.Poll()
+		player.Poll()
+		for _, key := range input.Keys {
+			if got, want := player.IsKeyDown(key), source.IsKeyDown(key); got != want {
+				t.Fatalf("tick %d: IsKeyDown(%d) = %v, want %v", tick, key, got, want)
+			}
+			if got, want := player.IsKeyPressed(key), source.IsKeyPressed(key); got != want {
+				t.Fatalf("tick %d: IsKeyPressed(%d) = %v, want %v", tick, key, got, want)
+			}
+		}
+	}
+
+	if !player.Done() {
+		t.Error("Done() = false after every tick was played")
+	}
+	player.Poll()
+	for _, key := range input.Keys {
+		if player.IsKeyDown(key) {
+			t.Errorf("IsKeyDown(%d) = true after the replay ended", key)
+		}
+	}
+}
+
+func TestPlaybackReproducesGame(t *testing.T) {
+	r, want := record(t, 42, 20000)
+
+	data, err := r.MarshalBinary()
+	if err != nil {
+		t.Fatalf("MarshalBinary() error = %v", err)
+	}
+	loaded := &Replay{}
+	if err := loaded.UnmarshalBinary(data); err != nil {
+		t.Fatalf("UnmarshalBinary() error = %v", err)
+	}
+
+	g, err := game.NewHeadless(loaded.Config, NewPlayer(loaded))
+	if err != nil {
+		t.Fatalf("NewHeadless() error = %v", err)
+	}
+	g.PlayLevels(loaded.Levels)
+	g.Initialize()
+	for !g.InputDone() {
+		g.Update(game.TickDuration)
+	}
+
+	if g.Ticks() != 20000 {
+		t.Errorf("Ticks() = %d, want 20000", g.Ticks())
+	}
+	got, err := json.Marshal(g.State())
+	if err != nil {
+		t.Fatalf("marshal state: %v", err)
+	}
+	if string(got) != string(want) {
+		t.Errorf("played back state differs from recorded\n got: %s\nwant: %s", got, want)
+	}
+}
+
+func TestUnmarshalRejectsBadData(t *testing.T) {
+	r, _ := record(t, 1, 500)
+	valid, err := r.MarshalBinary()
+	if err != nil {
+		t.Fatalf("MarshalBinary() error = %v", err)
+	}
+
+	old := binary.AppendUvarint([]byte(magic), 1)
+	future := binary.AppendUvarint([]byte(magic), Version+1)
+
+	tests := []struct {
+		name string
+		data []byte
+		want error
+	}{
+		{"empty", nil, ErrNotReplay},
+		{"wrong magic", []byte("PNG\x00 not a replay"), ErrNotReplay},
+		{"truncated", valid[:len(valid)-2], io.ErrUnexpectedEOF},
+		{"header only", []byte(magic), io.ErrUnexpectedEOF},
+		{"version without levels", old, nil},
+		{"future version", future, nil},
+		{"trailing bytes", append(valid[:len(valid):len(valid)], 0), nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := (&Replay{}).UnmarshalBinary(tt.data)
+			if err == nil {
+				t.Fatal("UnmarshalBinary() error = nil, want an error")
+			}
+			if tt.want != nil && !errors.Is(err, tt.want) {
+				t.Errorf("UnmarshalBinary() error = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestReplayKeepsItsLevels(t *testing.T) {
+	level, err := levels.Parse([]byte("{\"name\": \"Recorded\"}\nYYY\nG.G\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := &Replay{Config: config.Default(), Levels: []*levels.Level{level}, Keys: input.Keys}
+	data, err := r.MarshalBinary()
+	if err != nil {
+		t.Fatalf("MarshalBinary() error = %v", err)
+	}
+
+	loaded := &Replay{}
+	if err := loaded.UnmarshalBinary(data); err != nil {
+		t.Fatalf("UnmarshalBinary() error = %v", err)
+	}
+	if !reflect.DeepEqual(loaded.Levels, r.Levels) {
+		t.Errorf("Levels = %+v, want %+v", loaded.Levels, r.Levels)
+	}
+
+	// A level this build cannot read the same way is rejected, not swapped
+	// for another
+	unknown := bytes.Replace(data, []byte("G.G"), []byte("G.Z"), 1)
+	if err := (&Replay{}).UnmarshalBinary(unknown); err == nil || !strings.Contains(err.Error(), "level 1") {
+		t.Errorf("UnmarshalBinary() with an unknown brick error = %v, want it to name the level", err)
+	}
+}
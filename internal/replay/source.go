@@ -0,0 +1,130 @@
+package replay
+
+import (
+	"breakout/internal/config"
+	"breakout/internal/input"
+	"breakout/internal/levels"
+	"slices"
+)
+
+// Recorder is an input source that passes another source through unchanged
+// while recording what it reports on every tick
+type Recorder struct {
+	source input.Source
+	replay *Replay
+}
+
+// NewRecorder records the input read from source. The seed, config and the
+// levels the game plays are stored in the replay so it can recreate the game.
+func NewRecorder(source input.Source, seed int64, cfg config.Config, played []*levels.Level) *Recorder {
+	return &Recorder{
+		source: source,
+		replay: &Replay{
+			Seed:   seed,
+			Config: cfg,
+			Levels: played,
+			Keys:   slices.Clone(input.Keys),
+		},
+	}
+}
+
+// Poll advances the wrapped source and records its keys for the new tick
+func (r *Recorder) Poll() {
+	r.source.Poll()
+
+	var down, pressed uint64
+	for i, key := range r.replay.Keys {
+		if r.source.IsKeyDown(key) {
+			down |= 1 << i
+		}
+		if r.source.IsKeyPressed(key) {
+			pressed |= 1 << i
+		}
+	}
+
+	runs := r.replay.Runs
+	if n := len(runs); n > 0 && runs[n-1].Down == down && runs[n-1].Pressed == pressed {
+		runs[n-1].Ticks++
+		return
+	}
+	r.replay.Runs = append(runs, Run{Down: down, Pressed: pressed, Ticks: 1})
+}
+
+// IsKeyDown reports the wrapped source's key state
+func (r *Recorder) IsKeyDown(key int32) bool {
+	return r.source.IsKeyDown(key)
+}
+
+// IsKeyPressed reports the wrapped source's key state
+func (r *Recorder) IsKeyPressed(key int32) bool {
+	return r.source.IsKeyPressed(key)
+}
+
+// Replay returns the session recorded so far
+func (r *Recorder) Replay() *Replay {
+	return r.replay
+}
+
+// Player is an input source that plays back a replay one tick per Poll. Once
+// the replay runs out it reports no keys and Done returns true.
+type Player struct {
+	replay  *Replay
+	bits    map[int32]uint
+	run     int
+	left    uint64
+	down    uint64
+	pressed uint64
+}
+
+// NewPlayer creates a source playing back the given replay
+func NewPlayer(r *Replay) *Player {
+	bits := make(map[int32]uint, len(r.Keys))
+	for i, key := range r.Keys {
+		bits[key] = uint(i)
+	}
+	return &Player{replay: r, bits: bits}
+}
+
+// Poll moves on to the next recorded tick
+func (p *Player) Poll() {
+	for p.left == 0 && p.run < len(p.replay.Runs) {
+		p.left = p.replay.Runs[p.run].Ticks
+		p.run++
+	}
+	if p.left == 0 {
+		p.down, p.pressed = 0, 0
+		return
+	}
+
+	run := p.replay.Runs[p.run-1]
+	p.down, p.pressed = run.Down, run.Pressed
+	p.left--
+}
+
+// Done reports whether every recorded tick has been played
+func (p *Player) Done() bool {
+	if p.left > 0 {
+		return false
+	}
+	for _, run := range p.replay.Runs[p.run:] {
+		if run.Ticks > 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// IsKeyDown reports whether the key was held on the current tick
+func (p *Player) IsKeyDown(key int32) bool {
+	return p.has(p.down, key)
+}
+
+// IsKeyPressed reports whether the key was pressed on the current tick
+func (p *Player) IsKeyPressed(key int32) bool {
+	return p.has(p.pressed, key)
+}
+
+func (p *Player) has(mask uint64, key int32) bool {
+	bit, ok := p.bits[key]
+	return ok && mask&(1<<bit) != 0
+}
@@ -0,0 +1,117 @@
+// Package rules runs the data-driven rules that shape a game's progression.
+// The game reports what happens through the Engine, and applies the effects
+// of whichever rules fire.
+package rules
+
+import (
+	"breakout/internal/config"
+	"encoding/json"
+)
+
+// Engine decides which of a game's rules fire. It remembers how often each
+// rule has fired, so once rules fire a single time per game.
+type Engine struct {
+	rules       []config.Rule
+	fired       []int32
+	ticks       int64
+	tickSeconds float32
+}
+
+// NewEngine creates an engine for a new game. tickSeconds is the length of a
+// tick, for timed triggers.
+func NewEngine(rules []config.Rule, tickSeconds float32) *Engine {
+	return &Engine{
+		rules:       rules,
+		fired:       make([]int32, len(rules)),
+		tickSeconds: tickSeconds,
+	}
+}
+
+// SetRules switches to a new set of rules part way through a game. Rules
+// keep their fired count across the switch when their name is unchanged.
+func (e *Engine) SetRules(rules []config.Rule) {
+	fired := make([]int32, len(rules))
+	for i, r := range rules {
+		for j, old := range e.rules {
+			if old.Name == r.Name {
+				fired[i] = e.fired[j]
+			}
+		}
+	}
+	e.rules, e.fired = rules, fired
+}
+
+// Fired returns how many times the named rule has fired this game
+func (e *Engine) Fired(name string) int32 {
+	for i, r := range e.rules {
+		if r.Name == name {
+			return e.fired[i]
+		}
+	}
+	return 0
+}
+
+// BrickHit reports a hit on a brick of the given type
+func (e *Engine) BrickHit(brick string) []config.Effect {
+	return e.fire(config.EventBrickHit, func(t config.Trigger, _ int32) bool {
+		return t.Brick == brick
+	})
+}
+
+// CeilingHit reports the ball bouncing off the top wall
+func (e *Engine) CeilingHit() []config.Effect {
+	return e.fire(config.EventCeilingHit, func(config.Trigger, int32) bool {
+		return true
+	})
+}
+
+// LevelStart reports the start of a level, counting from 1
+func (e *Engine) LevelStart(level int32) []config.Effect {
+	return e.fire(config.EventLevelStart, func(t config.Trigger, _ int32) bool {
+		return t.Level == 0 || t.Level == level
+	})
+}
+
+// Tick reports a tick of play, once the ball has moved, along with the number
+// of bricks hit so far. Counted and timed triggers fire here, at most once per
+// tick each, so a repeated rule whose threshold was passed several times over
+// catches up on the following ticks.
+func (e *Engine) Tick(hits int32) []config.Effect {
+	e.ticks++
+	elapsed := float32(e.ticks) * e.tickSeconds
+
+	effects := e.fire(config.EventBrickHits, func(t config.Trigger, fired int32) bool {
+		return hits >= t.Count*(fired+1)
+	})
+	return append(effects, e.fire(config.EventElapsed, func(t config.Trigger, fired int32) bool {
+		return elapsed >= t.Seconds*float32(fired+1)
+	})...)
+}
+
+// fire fires every rule for the event whose trigger matches, given how often
+// the rule has fired so far, and returns their effects in rule order
+func (e *Engine) fire(event string, matches func(t config.Trigger, fired int32) bool) []config.Effect {
+	var effects []config.Effect
+	for i, r := range e.rules {
+		if r.Trigger.Event != event || (r.Policy == config.PolicyOnce && e.fired[i] > 0) {
+			continue
+		}
+		if matches(r.Trigger, e.fired[i]) {
+			e.fired[i]++
+			effects = append(effects, r.Effect)
+		}
+	}
+	return effects
+}
+
+// MarshalJSON encodes the time played and how often each rule has fired
+func (e *Engine) MarshalJSON() ([]byte, error) {
+	fired := make(map[string]int32, len(e.rules))
+	for i, r := range e.rules {
+		fired[r.Name] = e.fired[i]
+	}
+	return json.Marshal(struct {
+		Ticks int64            `json:"ticks"`
+		Fired map[string]int32 `json:"fired"`
+	}{e.ticks, fired})
+}
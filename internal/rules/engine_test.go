@@ -0,0 +1,100 @@
+package rules
+
+import (
+	"breakout/internal/config"
+	"testing"
+)
+
+func rule(name string, trigger config.Trigger, policy string) config.Rule {
+	return config.Rule{
+		Name:    name,
+		Trigger: trigger,
+		Effect:  config.Effect{Action: config.ActionBallSpeed, Factor: 1.1},
+		Policy:  policy,
+	}
+}
+
+func TestBrickHitsOnceAndRepeat(t *testing.T) {
+	e := NewEngine([]config.Rule{
+		rule("once", config.Trigger{Event: config.EventBrickHits, Count: 4}, config.PolicyOnce),
+		rule("every", config.Trigger{Event: config.EventBrickHits, Count: 4}, config.PolicyRepeat),
+	}, 0.01)
+
+	for hits := int32(0); hits <= 12; hits++ {
+		e.Tick(hits)
+	}
+	if got := e.Fired("once"); got != 1 {
+		t.Errorf("Fired(once) after 12 hits = %d, want 1", got)
+	}
+	if got := e.Fired("every"); got != 3 {
+		t.Errorf("Fired(every) after 12 hits = %d, want 3", got)
+	}
+
+	// A burst of hits is caught up one firing per tick
+	e.Tick(20)
+	e.Tick(20)
+	if got := e.Fired("every"); got != 5 {
+		t.Errorf("Fired(every) after 20 hits = %d, want 5", got)
+	}
+}
+
+func TestBrickTypeAndCeiling(t *testing.T) {
+	e := NewEngine([]config.Rule{
+		rule("red", config.Trigger{Event: config.EventBrickHit, Brick: "red"}, config.PolicyOnce),
+		rule("ceiling", config.Trigger{Event: config.EventCeilingHit}, config.PolicyRepeat),
+	}, 0.01)
+
+	if got := e.BrickHit("green"); len(got) != 0 {
+		t.Errorf("BrickHit(green) = %v, want no effects", got)
+	}
+	if got := e.BrickHit("red"); len(got) != 1 || got[0].Factor != 1.1 {
+		t.Errorf("BrickHit(red) = %v, want the speed-up", got)
+	}
+	if got := e.BrickHit("red"); len(got) != 0 {
+		t.Errorf("second BrickHit(red) = %v, want no effects", got)
+	}
+
+	e.CeilingHit()
+	e.CeilingHit()
+	if got := e.Fired("ceiling"); got != 2 {
+		t.Errorf("Fired(ceiling) = %d, want 2", got)
+	}
+}
+
+func TestElapsedAndLevelStart(t *testing.T) {
+	e := NewEngine([]config.Rule{
+		rule("minute", config.Trigger{Event: config.EventElapsed, Seconds: 1}, config.PolicyRepeat),
+		rule("level 2", config.Trigger{Event: config.EventLevelStart, Level: 2}, config.PolicyOnce),
+		rule("any level", config.Trigger{Event: config.EventLevelStart}, config.PolicyRepeat),
+	}, 0.25)
+
+	for range 11 {
+		e.Tick(0)
+	}
+	if got := e.Fired("minute"); got != 2 {
+		t.Errorf("Fired(minute) after 2.75s = %d, want 2", got)
+	}
+
+	e.LevelStart(1)
+	if got := e.LevelStart(2); len(got) != 2 {
+		t.Errorf("LevelStart(2) = %v, want both level rules", got)
+	}
+	if got := e.Fired("any level"); got != 2 {
+		t.Errorf("Fired(any level) = %d, want 2", got)
+	}
+}
+
+func TestSetRulesKeepsCounts(t *testing.T) {
+	shrink := rule("shrink", config.Trigger{Event: config.EventCeilingHit}, config.PolicyOnce)
+	e := NewEngine([]config.Rule{shrink}, 0.01)
+	e.CeilingHit()
+
+	faster := rule("faster", config.Trigger{Event: config.EventCeilingHit}, config.PolicyOnce)
+	e.SetRules([]config.Rule{faster, shrink})
+	if got := e.CeilingHit(); len(got) != 1 {
+		t.Errorf("CeilingHit() after SetRules = %v, want only the new rule", got)
+	}
+	if e.Fired("shrink") != 1 || e.Fired("faster") != 1 {
+		t.Errorf("Fired(shrink), Fired(faster) = %d, %d, want 1, 1", e.Fired("shrink"), e.Fired("faster"))
+	}
+}
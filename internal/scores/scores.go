@@ -0,0 +1,110 @@
+// Package scores keeps the high-score table. Every entry is tagged with the
+// difficulty it was played on, and entries on different difficulties are
+// never ranked against each other.
+package scores
+
+import (
+	"cmp"
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"time"
+)
+
+// Keep is how many entries the table keeps for each difficulty
+const Keep = 10
+
+// Entry is a single finished game
+type Entry struct {
+	Score      int32     `json:"score"`
+	Difficulty string    `json:"difficulty"`
+	Won        bool      `json:"won"`
+	Time       time.Time `json:"time"`
+}
+
+// Table holds the best games for every difficulty
+type Table struct {
+	Entries []Entry `json:"entries"`
+}
+
+// UserPath returns the user's high-score file, next to the user config
+func UserPath() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "breakout", "scores.json"), nil
+}
+
+// Load reads a table from path. A missing file is an empty table.
+func Load(path string) (*Table, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return &Table{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	t := &Table{}
+	if err := json.Unmarshal(data, t); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// Save writes the table to path, creating its directory if needed
+func (t *Table) Save(path string) error {
+	data, err := json.MarshalIndent(t, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0o644)
+}
+
+// Add records a game and returns its rank among games on the same difficulty,
+// starting at 1, or 0 if it did not make the table. Ties rank below the
+// games already in the table.
+func (t *Table) Add(e Entry) int {
+	rank := 1
+	for _, other := range t.Entries {
+		if other.Difficulty == e.Difficulty && other.Score >= e.Score {
+			rank++
+		}
+	}
+
+	t.Entries = append(t.Entries, e)
+	slices.SortStableFunc(t.Entries, func(a, b Entry) int {
+		return cmp.Compare(b.Score, a.Score)
+	})
+
+	// Keep only the best games of each difficulty
+	counts := make(map[string]int)
+	t.Entries = slices.DeleteFunc(t.Entries, func(entry Entry) bool {
+		counts[entry.Difficulty]++
+		return counts[entry.Difficulty] > Keep
+	})
+
+	if rank > Keep {
+		return 0
+	}
+	return rank
+}
+
+// Top returns the best n games on the given difficulty, best first. A
+// negative n returns all of them.
+func (t *Table) Top(difficulty string, n int) []Entry {
+	var top []Entry
+	for _, e := range t.Entries {
+		if e.Difficulty == difficulty && (n < 0 || len(top) < n) {
+			top = append(top, e)
+		}
+	}
+	return top
+}
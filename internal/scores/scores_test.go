@@ -0,0 +1,87 @@
+package scores
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestAddRanksWithinDifficulty(t *testing.T) {
+	table := &Table{}
+	add := func(score int32, difficulty string) int {
+		return table.Add(Entry{Score: score, Difficulty: difficulty})
+	}
+
+	if got := add(50, "normal"); got != 1 {
+		t.Errorf("Add() of the first game = %d, want 1", got)
+	}
+	if got := add(900, "easy"); got != 1 {
+		t.Errorf("Add() of the first easy game = %d, want 1", got)
+	}
+	if got := add(70, "normal"); got != 1 {
+		t.Errorf("Add() of a new best = %d, want 1", got)
+	}
+	if got := add(50, "normal"); got != 3 {
+		t.Errorf("Add() of a tie = %d, want 3", got)
+	}
+
+	var got []int32
+	for _, e := range table.Top("normal", -1) {
+		got = append(got, e.Score)
+	}
+	if want := []int32{70, 50, 50}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Top(normal) scores = %v, want %v", got, want)
+	}
+	if top := table.Top("easy", 5); len(top) != 1 || top[0].Score != 900 {
+		t.Errorf("Top(easy) = %+v, want only the easy game", top)
+	}
+}
+
+func TestAddKeepsBestPerDifficulty(t *testing.T) {
+	table := &Table{}
+	table.Add(Entry{Score: 5, Difficulty: "hard"})
+	for i := range Keep {
+		table.Add(Entry{Score: int32(100 + i), Difficulty: "normal"})
+	}
+
+	if got := table.Add(Entry{Score: 1, Difficulty: "normal"}); got != 0 {
+		t.Errorf("Add() of a score below a full table = %d, want 0", got)
+	}
+	if got := table.Add(Entry{Score: 200, Difficulty: "normal"}); got != 1 {
+		t.Errorf("Add() of a new best = %d, want 1", got)
+	}
+
+	normal := table.Top("normal", -1)
+	if len(normal) != Keep {
+		t.Fatalf("len(Top(normal)) = %d, want %d", len(normal), Keep)
+	}
+	if last := normal[Keep-1].Score; last != 101 {
+		t.Errorf("lowest kept normal score = %d, want 101", last)
+	}
+	if len(table.Top("hard", -1)) != 1 {
+		t.Error("a full normal table pushed out a hard game")
+	}
+}
+
+func TestSaveAndLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "breakout", "scores.json")
+
+	table, err := Load(path)
+	if err != nil || len(table.Entries) != 0 {
+		t.Fatalf("Load() of a missing file = %+v, %v, want an empty table", table, err)
+	}
+
+	table.Add(Entry{Score: 42, Difficulty: "atari", Won: true, Time: time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)})
+	if err := table.Save(path); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	got, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if !reflect.DeepEqual(got, table) {
+		t.Errorf("Load() = %+v, want %+v", got, table)
+	}
+}
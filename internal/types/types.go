@@ -1,15 +1,35 @@
 package types
 
-import rl "github.com/gen2brain/raylib-go/raylib"
+import (
+	"math"
 
-// Vector2 represents a 2D vector with integer coordinates
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
+
+// Vector2 represents a 2D vector with sub-pixel coordinates
 type Vector2 struct {
-	X, Y int32
+	X, Y float32
 }
 
 // ToRaylib converts to raylib Vector2
 func (v Vector2) ToRaylib() rl.Vector2 {
-	return rl.Vector2{X: float32(v.X), Y: float32(v.Y)}
+	return rl.Vector2{X: v.X, Y: v.Y}
+}
+
+// GridPos represents a cell in an integer grid
+type GridPos struct {
+	Col, Row int32
+}
+
+// Lerp linearly interpolates between a and b by t
+func Lerp(a, b, t float32) float32 {
+	return a + (b-a)*t
+}
+
+// Snap rounds a sub-pixel coordinate to the nearest whole pixel. Positions are
+// only snapped when drawing, never in the simulation.
+func Snap(v float32) int32 {
+	return int32(math.Round(float64(v)))
 }
 
 // Rectangle represents a rectangle shape
@@ -28,6 +48,22 @@ func (r Rectangle) ToRaylib() rl.Rectangle {
 	}
 }
 
+// Circle represents a circle shape
+type Circle struct {
+	Center Vector2
+	Radius float32
+}
+
+// Bounds returns the smallest rectangle containing the circle
+func (c Circle) Bounds() Rectangle {
+	return Rectangle{
+		X:      c.Center.X - c.Radius,
+		Y:      c.Center.Y - c.Radius,
+		Width:  2 * c.Radius,
+		Height: 2 * c.Radius,
+	}
+}
+
 // Collidable represents any object that can participate in collision detection
 type Collidable interface {
 	GetBounds() Rectangle
@@ -41,4 +77,4 @@ type Drawable interface {
 // Updatable represents any object that can be updated
 type Updatable interface {
 	Update(deltaTime float32)
-}
\ No newline at end of file
+}
@@ -1,47 +1,236 @@
 package main
 
 import (
+	"breakout/internal/config"
 	"breakout/internal/game"
-	"log"
+	"breakout/internal/input"
+	"breakout/internal/levels"
+	"breakout/internal/renderer"
+	"breakout/internal/replay"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"time"
 
 	rl "github.com/gen2brain/raylib-go/raylib"
 )
 
 const (
-	WindowWidth  = 768
-	WindowHeight = 1024
-	TargetFPS    = 144
+	// configWatchInterval is how often the config file is checked for changes
+	configWatchInterval = 500 * time.Millisecond
+
+	// fullscreenKey switches between a window and borderless fullscreen
+	fullscreenKey = rl.KeyF11
+
+	// minWindowWidth and minWindowHeight keep a resized window big enough to
+	// read, a quarter of the playfield's size
+	minWindowWidth  = config.PlayfieldWidth / 4
+	minWindowHeight = config.PlayfieldHeight / 4
 )
 
 func main() {
-	// Initialize raylib
-	rl.InitWindow(WindowWidth, WindowHeight, "Breakout")
-	defer rl.CloseWindow()
+	args := os.Args[1:]
+
+	var err error
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		err = runCommand(args[0], args[1:])
+	} else {
+		err = runPlay(args)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "breakout: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func runCommand(name string, args []string) error {
+	switch name {
+	case "simulate":
+		return runSimulate(args)
+	case "replay":
+		return runReplay(args)
+	case "config":
+		return runConfig(args)
+	case "edit":
+		return runEdit(args)
+	default:
+		return fmt.Errorf("unknown command %q", name)
+	}
+}
+
+// runPlay plays the game from the keyboard, optionally recording a replay.
+// Unless a difficulty was chosen in the config, environment or flags, it is
+// picked from a menu first, in place of the default one. Changes to the config
+// file and the settings screen are applied while playing, except when
+// recording, as a replay holds a single config.
+func runPlay(args []string) error {
+	fs := flag.NewFlagSet("breakout", flag.ContinueOnError)
+	loader := addConfigFlags(fs)
+	record := fs.String("record", "", "record the session to this replay file")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	eff, err := loadConfig(loader)
+	if err != nil {
+		return err
+	}
+
+	screen, closeWindow := openWindow(eff.Config.Window)
+	defer closeWindow()
+
+	keyboard := input.NewKeyboard()
+	var recorder *replay.Recorder
+	var playing *game.Game
+	fullscreen := func(on bool) {
+		if recorder != nil {
+			// A replay holds a single config, and the window is not part of it
+			setFullscreen(on)
+			return
+		}
+		switchFullscreen(playing, keyboard, loader, on)
+	}
+
+	if eff.Origins["game.difficulty"].Layer == config.LayerDefault {
+		name, ok := chooseDifficulty(screen, eff.Config, fullscreen)
+		if !ok {
+			return nil
+		}
+		loader.Difficulty = name
+		if eff, err = loader.Load(); err != nil {
+			return err
+		}
+	}
+	cfg := eff.Config
+	keyboard.Bind(bindings(cfg.Controls))
+	keeper := newScoreKeeper()
+
+	var source input.Source = keyboard
+	var watcher *config.Watcher
+	if *record != "" {
+		played, err := levels.Load(cfg.Game.LevelsDir)
+		if err != nil {
+			return err
+		}
+		recorder = replay.NewRecorder(keyboard, 0, cfg, played)
+		source = recorder
+	} else if path, err := loader.FilePath(); err == nil {
+		watcher = config.NewWatcher(path, configWatchInterval)
+	}
+
+	setup := func(g *game.Game) {
+		playing = g
+		if recorder != nil {
+			g.PlayLevels(recorder.Replay().Levels)
+		} else {
+			// Escape opens the settings screen rather than closing the window
+			rl.SetExitKey(rl.KeyNull)
+			g.EnableSettings(func(values map[string]string) error {
+				return saveSettings(g, keyboard, loader, values)
+			})
+		}
+	}
+	frame := func(g *game.Game) {
+		if g.EditRequested() {
+			editLevel(screen, g, keyboard, loader)
+		}
+		// The settings screen reads the keyboard itself
+		if !g.InSettings() {
+			keyboard.Capture()
+		}
+		keeper.update(g)
+		if watcher != nil {
+			reloadConfig(g, watcher, keyboard, loader)
+		}
+	}
+	if _, err := runWindowed(screen, cfg, source, fullscreen, setup, frame); err != nil {
+		return err
+	}
+
+	if recorder != nil {
+		return recorder.Replay().Save(*record)
+	}
+	return nil
+}
+
+// reloadConfig reloads every config layer when the config file changes and
+// applies the result
+func reloadConfig(g *game.Game, watcher *config.Watcher, keyboard *input.Keyboard, loader *config.Loader) {
+	if !watcher.Changed() {
+		return
+	}
+
+	eff, err := loadConfig(loader)
+	if err != nil {
+		g.ShowConfigError(err)
+		return
+	}
+	applyConfig(g, keyboard, eff.Config)
+}
 
+// openWindow opens the game window and audio device. It returns the screen to
+// draw the playfield on and a function that closes everything. Escape closes
+// the window unless the caller clears the exit key to use Escape itself.
+func openWindow(cfg config.WindowConfig) (*renderer.Screen, func()) {
+	if cfg.Resizable {
+		rl.SetConfigFlags(rl.FlagWindowResizable)
+	}
+	rl.InitWindow(cfg.Width, cfg.Height, cfg.Title)
+	rl.SetWindowMinSize(minWindowWidth, minWindowHeight)
+	if cfg.Fullscreen {
+		rl.ToggleBorderlessWindowed()
+	}
 	rl.InitAudioDevice()
-	defer rl.CloseAudioDevice()
+	rl.SetTargetFPS(cfg.TargetFPS)
 
-	rl.SetTargetFPS(TargetFPS)
+	screen := renderer.NewScreen()
+	return screen, func() {
+		screen.Unload()
+		rl.CloseAudioDevice()
+		rl.CloseWindow()
+	}
+}
 
+// handleWindowKeys handles the keys that control the window rather than the
+// game. Call it once per rendered frame. F11 switches the display mode with
+// fullscreen, which is setFullscreen unless the mode is saved as well.
+func handleWindowKeys(fullscreen func(on bool)) {
+	if rl.IsKeyPressed(fullscreenKey) {
+		fullscreen(!rl.IsWindowState(rl.FlagBorderlessWindowedMode))
+	}
+}
+
+// runWindowed runs a game in the open window, reading from the given source
+// until the window is closed or the player quits, and returns its final state.
+// F11 switches the display mode with fullscreen. setup, if not nil, is called
+// once the game is created, before it starts, and frame, if not nil, once per
+// rendered frame before the game updates.
+func runWindowed(screen *renderer.Screen, cfg config.Config, in input.Source, fullscreen func(on bool), setup, frame func(*game.Game)) (*game.State, error) {
 	// Create and initialize game
-	g, err := game.New()
+	g, err := game.New(cfg, in)
 	if err != nil {
-		log.Fatalf("Failed to create game: %v", err)
+		return nil, fmt.Errorf("failed to create game: %w", err)
 	}
 	defer g.Cleanup()
 
+	if setup != nil {
+		setup(g)
+	}
 	g.Initialize()
 
 	// Main game loop
-	for !rl.WindowShouldClose() {
-		deltaTime := rl.GetFrameTime()
-
-		rl.BeginDrawing()
-		rl.ClearBackground(rl.Black)
+	for !rl.WindowShouldClose() && !g.QuitRequested() {
+		handleWindowKeys(fullscreen)
+		if frame != nil {
+			frame(g)
+		}
+		g.Update(rl.GetFrameTime())
 
-		g.Update(deltaTime)
+		screen.BeginFrame()
 		g.Draw()
-
-		rl.EndDrawing()
+		screen.EndFrame()
 	}
+
+	return g.State(), nil
 }
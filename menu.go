@@ -0,0 +1,53 @@
+package main
+
+import (
+	"breakout/internal/config"
+	"breakout/internal/renderer"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
+
+// chooseDifficulty lists the difficulty presets in the open window until one
+// is picked with Space, Enter or a click, starting from cfg's difficulty. It
+// reports false if the window is closed or Escape pressed first. F11 switches
+// the display mode with fullscreen.
+func chooseDifficulty(screen *renderer.Screen, cfg config.Config, fullscreen func(on bool)) (string, bool) {
+	presets := config.Presets()
+	titles := make([]string, len(presets))
+	selected := 0
+	for i, p := range presets {
+		titles[i] = p.Title
+		if p.Name == cfg.Game.Difficulty {
+			selected = i
+		}
+	}
+
+	r := renderer.New()
+	for !rl.WindowShouldClose() {
+		handleWindowKeys(fullscreen)
+
+		// The mouse is in playfield coordinates, see renderer.Screen
+		hovered, onItem := r.MenuItemAt(len(titles), rl.GetMousePosition().Y)
+		if onItem && rl.GetMouseDelta() != (rl.Vector2{}) {
+			selected = hovered
+		}
+
+		switch {
+		case rl.IsKeyPressed(rl.KeyEscape):
+			return "", false
+		case onItem && rl.IsMouseButtonPressed(rl.MouseButtonLeft):
+			return presets[hovered].Name, true
+		case rl.IsKeyPressed(rl.KeyW) || rl.IsKeyPressed(rl.KeyUp):
+			selected = (selected + len(presets) - 1) % len(presets)
+		case rl.IsKeyPressed(rl.KeyS) || rl.IsKeyPressed(rl.KeyDown):
+			selected = (selected + 1) % len(presets)
+		case rl.IsKeyPressed(rl.KeySpace) || rl.IsKeyPressed(rl.KeyEnter):
+			return presets[selected].Name, true
+		}
+
+		screen.BeginFrame()
+		r.DrawMenu("Choose Difficulty", titles, selected, presets[selected].Description)
+		screen.EndFrame()
+	}
+	return "", false
+}
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"breakout/internal/game"
+	"breakout/internal/replay"
+	"flag"
+	"fmt"
+)
+
+// runReplay plays back a recorded session on the levels it was recorded on, in
+// a window or headless. Headless playback runs every recorded tick as fast as
+// possible and prints the final state as JSON.
+func runReplay(args []string) error {
+	fs := flag.NewFlagSet("replay", flag.ContinueOnError)
+	headless := fs.Bool("headless", false, "play back without a window and print the final state")
+	expectScore := fs.Int("expect-score", -1, "fail unless the replay ends with this score")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		return fmt.Errorf("usage: breakout replay [--headless] [--expect-score N] <file>")
+	}
+
+	r, err := replay.Load(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	player := replay.NewPlayer(r)
+
+	var state *game.State
+	if *headless {
+		g, err := game.NewHeadless(r.Config, player)
+		if err != nil {
+			return err
+		}
+		g.PlayLevels(r.Levels)
+		g.Initialize()
+		for !player.Done() {
+			g.Tick()
+		}
+		state = g.State()
+
+		if err := printJSON(state); err != nil {
+			return err
+		}
+	} else {
+		screen, closeWindow := openWindow(r.Config.Window)
+		setup := func(g *game.Game) {
+			g.PlayLevels(r.Levels)
+		}
+		state, err = runWindowed(screen, r.Config, player, setFullscreen, setup, nil)
+		closeWindow()
+		if err != nil {
+			return err
+		}
+		fmt.Printf("replayed %d ticks, final score %d\n", r.Ticks(), state.Score)
+	}
+
+	if *expectScore >= 0 && state.Score != int32(*expectScore) {
+		return fmt.Errorf("replay ended with score %d, expected %d", state.Score, *expectScore)
+	}
+	return nil
+}
@@ -0,0 +1,64 @@
+package main
+
+import (
+	"breakout/internal/config"
+	"breakout/internal/game"
+	"breakout/internal/scores"
+	"fmt"
+	"os"
+	"time"
+)
+
+// shownHighScores is how many high scores the game over screen lists
+const shownHighScores = 5
+
+// scoreKeeper records finished games in the user's high-score table and hands
+// the best scores on the game's difficulty to the game over screen
+type scoreKeeper struct {
+	path     string
+	table    *scores.Table
+	recorded bool
+}
+
+// newScoreKeeper loads the user's high-score table. If it cannot be read, no
+// scores are recorded rather than overwriting it.
+func newScoreKeeper() *scoreKeeper {
+	path, err := scores.UserPath()
+	var table *scores.Table
+	if err == nil {
+		table, err = scores.Load(path)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "breakout: warning: not recording high scores: %v\n", err)
+		return &scoreKeeper{}
+	}
+	return &scoreKeeper{path: path, table: table}
+}
+
+// update records the game once, on the first frame it is over. Games on
+// custom settings are not recorded.
+func (k *scoreKeeper) update(g *game.Game) {
+	if !g.IsOver() {
+		k.recorded = false
+		return
+	}
+	if k.recorded || k.table == nil {
+		return
+	}
+	k.recorded = true
+
+	state := g.State()
+	if state.Difficulty == config.CustomDifficulty {
+		return
+	}
+	k.table.Add(scores.Entry{
+		Score:      state.Score,
+		Difficulty: state.Difficulty,
+		Won:        state.GameWon,
+		Time:       time.Now(),
+	})
+	if err := k.table.Save(k.path); err != nil {
+		fmt.Fprintf(os.Stderr, "breakout: warning: saving high scores: %v\n", err)
+	}
+	g.SetHighScores(k.table.Top(state.Difficulty, shownHighScores))
+}
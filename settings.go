@@ -0,0 +1,97 @@
+package main
+
+import (
+	"breakout/internal/config"
+	"breakout/internal/game"
+	"breakout/internal/input"
+	"fmt"
+	"maps"
+	"os"
+	"slices"
+	"strconv"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
+
+// saveSettings writes settings changed on the settings screen to the config
+// file and applies the reloaded config. A value still overridden by the
+// environment or a flag is saved, but reported as not taking effect.
+func saveSettings(g *game.Game, keyboard *input.Keyboard, loader *config.Loader, values map[string]string) error {
+	if err := saveFileValues(loader, values); err != nil {
+		return err
+	}
+
+	eff, err := loadConfig(loader)
+	if err != nil {
+		return err
+	}
+	applyConfig(g, keyboard, eff.Config)
+
+	for _, p := range slices.Sorted(maps.Keys(values)) {
+		if origin := eff.Origins[p]; origin.Layer == config.LayerEnv || origin.Layer == config.LayerFlag {
+			return fmt.Errorf("saved, but %s overrides %s", origin, p)
+		}
+	}
+	return nil
+}
+
+// applyConfig hands a reloaded config to the game, and applies the parts the
+// game does not own: the key bindings and the display mode
+func applyConfig(g *game.Game, keyboard *input.Keyboard, cfg config.Config) {
+	g.Reconfigure(cfg)
+	keyboard.Bind(bindings(cfg.Controls))
+	// Not compared with g.Config(), which stays the same until the game next
+	// ticks, and never does while the settings screen is open
+	setFullscreen(cfg.Window.Fullscreen)
+}
+
+// switchFullscreen saves the display mode F11 switched to and applies it, as
+// Display on the settings screen does, so the setting and the window agree.
+// Before the game is created g is nil, and the file alone is changed along
+// with the window.
+func switchFullscreen(g *game.Game, keyboard *input.Keyboard, loader *config.Loader, on bool) {
+	values := map[string]string{"window.fullscreen": strconv.FormatBool(on)}
+	var err error
+	if g != nil {
+		err = saveSettings(g, keyboard, loader, values)
+	} else if err = saveFileValues(loader, values); err == nil {
+		setFullscreen(on)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "breakout: cannot switch the display mode: %v\n", err)
+	}
+}
+
+// saveFileValues writes values to the config file the loader reads
+func saveFileValues(loader *config.Loader, values map[string]string) error {
+	path, err := loader.FilePath()
+	if err != nil {
+		return err
+	}
+	return config.SetFileValues(path, values)
+}
+
+// bindings maps the keys the game reads to the keys the player chose for them
+func bindings(c config.ControlsConfig) map[int32]int32 {
+	out := make(map[int32]int32)
+	for key, name := range map[int32]string{
+		rl.KeyA:     c.Left,
+		rl.KeyD:     c.Right,
+		rl.KeyW:     c.Faster,
+		rl.KeyS:     c.Slower,
+		rl.KeySpace: c.Serve,
+		rl.KeyR:     c.Restart,
+	} {
+		// Validate has checked every name
+		out[key], _ = input.KeyByName(name)
+	}
+	return out
+}
+
+// setFullscreen switches the window to or from borderless fullscreen. It is a
+// variable so tests can follow it without a window.
+var setFullscreen = func(on bool) {
+	if rl.IsWindowState(rl.FlagBorderlessWindowedMode) != on {
+		rl.ToggleBorderlessWindowed()
+	}
+}
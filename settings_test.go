@@ -0,0 +1,69 @@
+package main
+
+import (
+	"breakout/internal/config"
+	"breakout/internal/game"
+	"breakout/internal/input"
+	"testing"
+)
+
+func TestSaveSettingsChangesMenuDifficulty(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	// As runPlay sets it up after hard is picked from the menu
+	loader := &config.Loader{Flags: map[string]string{}, Difficulty: "hard"}
+	eff, err := loadConfig(loader)
+	if err != nil {
+		t.Fatalf("loadConfig() error = %v", err)
+	}
+	keyboard := input.NewKeyboard()
+	g, err := game.NewHeadless(eff.Config, keyboard)
+	if err != nil {
+		t.Fatalf("NewHeadless() error = %v", err)
+	}
+	g.Initialize()
+
+	if err := saveSettings(g, keyboard, loader, map[string]string{"game.difficulty": "easy"}); err != nil {
+		t.Fatalf("saveSettings() error = %v, want the saved difficulty to apply", err)
+	}
+	// The game switches to the new config on its next tick
+	g.Tick()
+	if cfg := g.Config(); cfg.Game.Difficulty != "easy" || cfg.Game.Serves != 5 {
+		t.Errorf("Difficulty, Serves = %q, %d, want the easy preset", cfg.Game.Difficulty, cfg.Game.Serves)
+	}
+	if got := g.State().Difficulty; got != "easy" {
+		t.Errorf("State().Difficulty = %q, want a new easy game", got)
+	}
+	if eff, err := loader.Load(); err != nil || eff.Origins["game.difficulty"].Layer != config.LayerFile {
+		t.Errorf("game.difficulty after saving comes from %v (error %v), want the file", eff.Origins["game.difficulty"], err)
+	}
+}
+
+func TestDisplayToggledTwiceInOneSettingsSession(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	loader := &config.Loader{Flags: map[string]string{}}
+	eff, err := loadConfig(loader)
+	if err != nil {
+		t.Fatalf("loadConfig() error = %v", err)
+	}
+	keyboard := input.NewKeyboard()
+	g, err := game.NewHeadless(eff.Config, keyboard)
+	if err != nil {
+		t.Fatalf("NewHeadless() error = %v", err)
+	}
+	g.Initialize()
+
+	var switched []bool
+	defer func(f func(bool)) { setFullscreen = f }(setFullscreen)
+	setFullscreen = func(on bool) { switched = append(switched, on) }
+
+	// The settings screen does not tick the game, so it still holds the config
+	// from before the first save when the second is made
+	for _, on := range []string{"true", "false"} {
+		if err := saveSettings(g, keyboard, loader, map[string]string{"window.fullscreen": on}); err != nil {
+			t.Fatalf("saveSettings(%s) error = %v", on, err)
+		}
+	}
+	if len(switched) == 0 || switched[len(switched)-1] {
+		t.Errorf("setFullscreen calls = %v, want the window left windowed", switched)
+	}
+}
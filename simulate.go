@@ -0,0 +1,63 @@
+package main
+
+import (
+	"breakout/internal/game"
+	"breakout/internal/input"
+	"breakout/internal/levels"
+	"breakout/internal/replay"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+)
+
+// runSimulate runs the game without a window, driven by seeded random input,
+// and prints the final state as JSON
+func runSimulate(args []string) error {
+	fs := flag.NewFlagSet("simulate", flag.ContinueOnError)
+	frames := fs.Int("frames", 1000, "number of fixed ticks to simulate")
+	seed := fs.Int64("seed", 1, "seed for the random input source")
+	loader := addConfigFlags(fs)
+	record := fs.String("record", "", "record the session to this replay file")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *frames < 0 {
+		return fmt.Errorf("frames must not be negative, got %d", *frames)
+	}
+
+	eff, err := loadConfig(loader)
+	if err != nil {
+		return err
+	}
+	played, err := levels.Load(eff.Config.Game.LevelsDir)
+	if err != nil {
+		return err
+	}
+	recorder := replay.NewRecorder(input.NewRandom(*seed), *seed, eff.Config, played)
+
+	g, err := game.NewHeadless(eff.Config, recorder)
+	if err != nil {
+		return err
+	}
+	g.PlayLevels(played)
+	g.Initialize()
+
+	for i := 0; i < *frames; i++ {
+		g.Tick()
+	}
+
+	if *record != "" {
+		if err := recorder.Replay().Save(*record); err != nil {
+			return err
+		}
+	}
+	return printJSON(g.State())
+}
+
+// printJSON writes v to stdout as indented JSON
+func printJSON(v any) error {
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}